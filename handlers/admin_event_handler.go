@@ -234,6 +234,7 @@ func UpdateEvent(c *fiber.Ctx) error {
 
 	// Update timestamp
 	existingEvent.UpdatedAt = time.Now()
+	existingEvent.Revisi++
 
 	// Save the updated event to the database
 	if err := database.DB.Save(&existingEvent).Error; err != nil {
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusLayananDirencanakan = "Direncanakan"
+	StatusLayananBerjalan     = "Berjalan"
+	StatusLayananSelesai      = "Selesai"
+)
+
+var jenisKebutuhanValid = map[string]bool{
+	"medis":       true,
+	"psikososial": true,
+	"hukum":       true,
+	"rumah_aman":  true,
+	"ekonomi":     true,
+}
+
+var statusLayananValid = map[string]bool{
+	StatusLayananDirencanakan: true,
+	StatusLayananBerjalan:     true,
+	StatusLayananSelesai:      true,
+}
+
+func GetKebutuhanKorban(c *fiber.Ctx) error {
+	korbanID := c.Params("korban_id")
+	var kebutuhan []models.KebutuhanKorban
+	if err := database.DB.Where("korban_id = ?", korbanID).Order("tenggat_waktu asc").Find(&kebutuhan).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mengambil kebutuhan korban",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Daftar kebutuhan korban",
+		Data:    kebutuhan,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func CreateKebutuhanKorban(c *fiber.Ctx) error {
+	korbanID, err := strconv.ParseUint(c.FormValue("korban_id"), 10, 64)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid korban ID",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	var korban models.Korban
+	if err := database.DB.First(&korban, korbanID).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Korban tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+
+	kebutuhan := models.KebutuhanKorban{
+		KorbanID:            uint(korbanID),
+		JenisKebutuhan:      c.FormValue("jenis_kebutuhan"),
+		Deskripsi:           c.FormValue("deskripsi"),
+		LayananDirencanakan: c.FormValue("layanan_direncanakan"),
+		PenanggungJawab:     c.FormValue("penanggung_jawab"),
+		Status:              StatusLayananDirencanakan,
+	}
+	if !jenisKebutuhanValid[kebutuhan.JenisKebutuhan] {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Jenis kebutuhan harus salah satu dari: medis, psikososial, hukum, rumah_aman, ekonomi",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	if value := c.FormValue("tenggat_waktu"); value != "" {
+		tenggat, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid format for tenggat waktu, use yyyy-MM-dd",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		kebutuhan.TenggatWaktu = &tenggat
+	}
+
+	now := time.Now()
+	kebutuhan.CreatedAt = now
+	kebutuhan.UpdatedAt = now
+	if err := database.DB.Create(&kebutuhan).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menambah kebutuhan korban",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusCreated,
+		Status:  "success",
+		Message: "Berhasil menambah kebutuhan korban",
+		Data:    kebutuhan,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func UpdateKebutuhanKorban(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var kebutuhan models.KebutuhanKorban
+	if err := database.DB.First(&kebutuhan, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusNotFound,
+				Status:  "error",
+				Message: "Kebutuhan korban tidak ditemukan",
+			}
+			return c.Status(http.StatusNotFound).JSON(response)
+		}
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Database error",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	if value := c.FormValue("jenis_kebutuhan"); value != "" {
+		if !jenisKebutuhanValid[value] {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Jenis kebutuhan harus salah satu dari: medis, psikososial, hukum, rumah_aman, ekonomi",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		kebutuhan.JenisKebutuhan = value
+	}
+	if value := c.FormValue("deskripsi"); value != "" {
+		kebutuhan.Deskripsi = value
+	}
+	if value := c.FormValue("layanan_direncanakan"); value != "" {
+		kebutuhan.LayananDirencanakan = value
+	}
+	if value := c.FormValue("penanggung_jawab"); value != "" {
+		kebutuhan.PenanggungJawab = value
+	}
+	if value := c.FormValue("tenggat_waktu"); value != "" {
+		tenggat, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid format for tenggat waktu, use yyyy-MM-dd",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		kebutuhan.TenggatWaktu = &tenggat
+	}
+	if value := c.FormValue("status"); value != "" {
+		if !statusLayananValid[value] {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Status harus salah satu dari: Direncanakan, Berjalan, Selesai",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		if value == StatusLayananSelesai && kebutuhan.Status != StatusLayananSelesai {
+			now := time.Now()
+			kebutuhan.WaktuSelesai = &now
+		} else if value != StatusLayananSelesai {
+			kebutuhan.WaktuSelesai = nil
+		}
+		kebutuhan.Status = value
+	}
+
+	kebutuhan.UpdatedAt = time.Now()
+	if err := database.DB.Save(&kebutuhan).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mengupdate kebutuhan korban",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Berhasil mengupdate kebutuhan korban",
+		Data:    kebutuhan,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func DeleteKebutuhanKorban(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var kebutuhan models.KebutuhanKorban
+	if err := database.DB.First(&kebutuhan, id).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Kebutuhan korban tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	if err := database.DB.Delete(&kebutuhan).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menghapus kebutuhan korban",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Berhasil menghapus kebutuhan korban",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// getLayananBelumSelesai mengambil rencana layanan korban pada sebuah laporan yang belum selesai.
+func getLayananBelumSelesai(db *gorm.DB, noRegistrasi string) ([]models.KebutuhanKorban, error) {
+	var kebutuhan []models.KebutuhanKorban
+	err := db.
+		Joins("JOIN korbans ON korbans.id = kebutuhan_korbans.korban_id").
+		Where("korbans.no_registrasi = ? AND kebutuhan_korbans.status <> ?", noRegistrasi, StatusLayananSelesai).
+		Find(&kebutuhan).Error
+	return kebutuhan, err
+}
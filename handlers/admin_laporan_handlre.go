@@ -8,6 +8,7 @@ import (
 	"backend-pedika-fiber/helper"
 	"backend-pedika-fiber/models"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -191,7 +192,7 @@ func GetLaporanByNoRegistrasi(c *fiber.Ctx) error {
 	}
 
 	var pelaku []models.Pelaku
-	if err := db.Where("no_registrasi = ?", noRegistrasi).Find(&pelaku).Error; err != nil {
+	if err := db.Preload("Dokumentasi").Where("no_registrasi = ?", noRegistrasi).Find(&pelaku).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -201,7 +202,7 @@ func GetLaporanByNoRegistrasi(c *fiber.Ctx) error {
 	}
 
 	var korban []models.Korban
-	if err := db.Where("no_registrasi = ?", noRegistrasi).Find(&korban).Error; err != nil {
+	if err := db.Preload("Dokumentasi").Preload("Kebutuhan").Where("no_registrasi = ?", noRegistrasi).Find(&korban).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -210,6 +211,28 @@ func GetLaporanByNoRegistrasi(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 
+	var konsultasi []models.JanjiTemu
+	if err := db.Where("no_registrasi = ?", noRegistrasi).Order("waktu_dimulai asc").Find(&konsultasi).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to fetch konsultasi details",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	var sesiKonseling []models.SesiKonseling
+	if err := db.Where("no_registrasi = ?", noRegistrasi).Order("created_at asc").Find(&sesiKonseling).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to fetch sesi konseling details",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	adminID, _, _ := currentUserClaims(c)
+	sembunyikanCatatan(sesiKonseling, adminID)
+
 	var userMelihat models.User
 	if laporan.UserIDMelihat != nil {
 		if err := db.First(&userMelihat, *laporan.UserIDMelihat).Error; err != nil {
@@ -227,12 +250,16 @@ func GetLaporanByNoRegistrasi(c *fiber.Ctx) error {
 		TrackingLaporan []models.TrackingLaporan `json:"tracking_laporan"`
 		Pelaku          []models.Pelaku          `json:"pelaku"`
 		Korban          []models.Korban          `json:"korban"`
+		Konsultasi      []models.JanjiTemu       `json:"konsultasi"`
+		SesiKonseling   []models.SesiKonseling   `json:"sesi_konseling"`
 		UserMelihat     *models.User             `json:"user_melihat,omitempty"`
 	}{
 		Laporan:         laporan,
 		TrackingLaporan: trackingLaporan,
 		Pelaku:          pelaku,
 		Korban:          korban,
+		Konsultasi:      konsultasi,
+		SesiKonseling:   sesiKonseling,
 		UserMelihat:     nil,
 	}
 
@@ -240,11 +267,21 @@ func GetLaporanByNoRegistrasi(c *fiber.Ctx) error {
 		responseData.UserMelihat = &userMelihat
 	}
 
+	data, err := sanitizeResponseData(c, "laporan", noRegistrasi, responseData)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to prepare report detail",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
 	response := helper.ResponseWithData{
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "Report detail retrieved successfully",
-		Data:    responseData,
+		Data:    data,
 	}
 
 	return c.Status(http.StatusOK).JSON(response)
@@ -295,6 +332,7 @@ func AdminLihatLaporan(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	terbitkanLaporanAdmin(EventStatusLaporan, laporan)
 
 	response := helper.ResponseWithData{
 		Code:    http.StatusOK,
@@ -367,59 +405,40 @@ func AdminProsesLaporan(c *fiber.Ctx) error {
 	now := time.Now()
 	laporan.WaktuDiproses = &now
 
-	if err := db.Save(&laporan).Error; err != nil {
-		response := helper.ResponseWithOutData{
-			Code:    http.StatusInternalServerError,
-			Status:  "error",
-			Message: "Failed to update laporan",
-		}
-		return c.Status(http.StatusInternalServerError).JSON(response)
-	}
-
- 	var user models.User
-    if err := db.Where("id = ?", laporan.UserID).First(&user).Error; err != nil {
-        log.Printf("Failed to retrieve user for notification: %v", err)
-    }  else if user.NotificationToken != "" {
-		log.Println("User notification token:", user.NotificationToken)
-
-		notificationData := models.FCMNotificationData{
-			Type:      "report_status",
-			ReportID:  laporan.NoRegistrasi,
-			Status:    "in_progress",
-			UpdatedBy: userID,
-			UpdatedAt: now.Format(time.RFC3339),
-			Notes:     "Laporan sedang diverifikasi oleh tim",
-			// Deep Link Dummy di front End belum digunakan 
-			DeepLink:  "laporanku://reports/" + laporan.NoRegistrasi,   
-			// ImageURL: ,  INI OPTIONAL tpi tiati harus make size image yang sesuai 
+	notificationData := JenisStatusLaporan.Data(
+		PayloadLaporan{NoRegistrasi: laporan.NoRegistrasi, Status: "in_progress"},
+		userID,
+		"Laporan sedang diverifikasi oleh tim",
+		now,
+	)
+
+	// Status laporan dan notifikasi (outbox) disimpan dalam satu transaksi.
+	var notifikasi *models.Notification
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&laporan).Error; err != nil {
+			return err
 		}
-
-		notification, err := NewNotificationFromFCMData(
+		var err error
+		notifikasi, err = antrekanNotifikasiTemplate(tx,
 			laporan.UserID,
-			"Status Laporan Diperbarui",
-			"Laporan Anda dengan ID "+laporan.NoRegistrasi+" sedang diproses",
+			"laporan_diproses",
+			varsLaporan{NoRegistrasi: laporan.NoRegistrasi},
 			notificationData,
 			now,
 		)
-		if err != nil {
-			log.Println("Error create notif:", err)
-			return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to create notification",
-			})
-		}
-
-		// Simpan notifikasi ke database
-		if err := db.Create(&notification).Error; err != nil {
-			log.Printf("Failed to store notification: %v", err)
-		}
-
-		// Kirim push notification via FCM
-		if err := SendFCMNotification(user.NotificationToken, notificationData,*notification); err != nil {
-			log.Printf("Failed to send FCM notification: %v", err)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to process laporan: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to update laporan",
 		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	notifikasiTersimpan(notifikasi)
+	terbitkanLaporanAdmin(EventStatusLaporan, laporan)
 
 	// Sukses
 	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
@@ -559,7 +578,32 @@ func SelesaikanLaporan(c *fiber.Ctx) error {
     laporan.Status = "Selesai"
     now := time.Now()
     laporan.UpdatedAt = now
-    if err := db.Save(&laporan).Error; err != nil {
+
+    notificationData := JenisStatusLaporan.Data(
+        PayloadLaporan{NoRegistrasi: laporan.NoRegistrasi, Status: "completed"},
+        adminID, // ID admin yang menyelesaikan
+        "Laporan Anda telah selesai diproses",
+        now,
+    )
+
+    // Status laporan dan notifikasi (outbox) disimpan dalam satu transaksi.
+    var notifikasi *models.Notification
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Save(&laporan).Error; err != nil {
+            return err
+        }
+        var err error
+        notifikasi, err = antrekanNotifikasiTemplate(tx,
+            laporan.UserID,
+            "laporan_selesai",
+            varsLaporan{NoRegistrasi: laporan.NoRegistrasi},
+            notificationData,
+            now,
+        )
+        return err
+    })
+    if err != nil {
+        log.Printf("Failed to complete laporan: %v", err)
         response := helper.ResponseWithOutData{
             Code:    http.StatusInternalServerError,
             Status:  "error",
@@ -567,45 +611,23 @@ func SelesaikanLaporan(c *fiber.Ctx) error {
         }
         return c.Status(http.StatusInternalServerError).JSON(response)
     }
-
-    // Cari pengguna untuk notifikasi
-    var user models.User
-    if err := db.Where("id = ?", laporan.UserID).First(&user).Error; err != nil {
-        log.Printf("Failed to retrieve user for notification: %v", err)
-    } else if user.NotificationToken != "" {
-        log.Println("User notification token:", user.NotificationToken)
-
-        // Siapkan data notifikasi FCM
-        notificationData := models.FCMNotificationData{
-            Type:      "report_status",
-            ReportID:  laporan.NoRegistrasi,
-            Status:    "completed", // Status berbeda dari "in_progress"
-            UpdatedBy: adminID,     // ID admin yang menyelesaikan
-            UpdatedAt: now.Format(time.RFC3339),
-            Notes:     "Laporan Anda telah selesai diproses",
-            DeepLink:  "laporanku://reports/" + laporan.NoRegistrasi, // Deep link opsional
-        }
-
-        // Buat notifikasi untuk disimpan di database
-        notification, err := NewNotificationFromFCMData(
-            laporan.UserID,
-            "Laporan Selesai",
-            "Laporan Anda dengan ID "+laporan.NoRegistrasi+" telah selesai",
-            notificationData,
-            now,
-        )
-        if err != nil {
-            log.Printf("Error creating notification: %v", err)
-        } else {
-            // Simpan notifikasi ke database
-            if err := db.Create(notification).Error; err != nil {
-                log.Printf("Failed to store notification: %v", err)
-            }
-
-            // Kirim push notification via FCM
-            if err := SendFCMNotification(user.NotificationToken, notificationData,*notification); err != nil {
-                log.Printf("Failed to send FCM notification: %v", err)
-            }
+    notifikasiTersimpan(notifikasi)
+    terbitkanLaporanAdmin(EventStatusLaporan, laporan)
+
+    // Peringatan jika masih ada rencana layanan korban yang belum selesai
+    data := fiber.Map{
+        "no_registrasi": laporan.NoRegistrasi,
+        "status":        laporan.Status,
+        "updated_at":    laporan.UpdatedAt,
+    }
+    message := "Laporan completed successfully"
+    layananTerbuka, err := getLayananBelumSelesai(db, laporan.NoRegistrasi)
+    if err != nil {
+        log.Printf("Failed to check open victim services: %v", err)
+    } else if len(layananTerbuka) > 0 {
+        message = fmt.Sprintf("Laporan completed, tetapi masih ada %d layanan korban yang belum selesai", len(layananTerbuka))
+        data["peringatan"] = fiber.Map{
+            "layanan_belum_selesai": layananTerbuka,
         }
     }
 
@@ -613,12 +635,8 @@ func SelesaikanLaporan(c *fiber.Ctx) error {
     response := helper.ResponseWithData{
         Code:    http.StatusOK,
         Status:  "success",
-        Message: "Laporan completed successfully",
-        Data: fiber.Map{
-            "no_registrasi": laporan.NoRegistrasi,
-            "status":        laporan.Status,
-            "updated_at":    laporan.UpdatedAt,
-        },
+        Message: message,
+        Data:    data,
     }
 
     return c.Status(http.StatusOK).JSON(response)
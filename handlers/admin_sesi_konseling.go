@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	KehadiranHadir         = "Hadir"
+	KehadiranTidakHadir    = "Tidak hadir"
+	KehadiranBatalMendadak = "Batal mendadak"
+)
+
+var kehadiranValid = map[string]bool{
+	KehadiranHadir:         true,
+	KehadiranTidakHadir:    true,
+	KehadiranBatalMendadak: true,
+}
+
+// bolehLihatCatatan: catatan konselor bersifat rahasia, hanya konselor sesi dan
+// admin yang mencatatnya yang dapat membaca atau mengubahnya.
+func bolehLihatCatatan(sesi models.SesiKonseling, userID uint) bool {
+	return sesi.KonselorID == userID || sesi.DicatatOlehID == userID
+}
+
+func sembunyikanCatatan(sesi []models.SesiKonseling, userID uint) {
+	for i := range sesi {
+		if !bolehLihatCatatan(sesi[i], userID) {
+			sesi[i].CatatanKonselor = ""
+		}
+	}
+}
+
+// validasiLaporanSesi memastikan laporan yang ditautkan milik masyarakat yang sama
+// dengan pemohon janji temu.
+func validasiLaporanSesi(noRegistrasi string, janjiTemu models.JanjiTemu) error {
+	return validasiLaporanMilikUser(noRegistrasi, janjiTemu.UserID)
+}
+
+func parseTanggalTindakLanjut(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	tanggal, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &tanggal, nil
+}
+
+func AdminGetSesiKonseling(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	query := database.DB.Preload("JanjiTemu").Preload("Konselor").Order("created_at desc")
+	if konselorID := c.Query("konselor_id"); konselorID != "" {
+		query = query.Where("konselor_id = ?", konselorID)
+	}
+	if kehadiran := c.Query("kehadiran"); kehadiran != "" {
+		query = query.Where("kehadiran = ?", kehadiran)
+	}
+	if noRegistrasi := c.Query("no_registrasi"); noRegistrasi != "" {
+		query = query.Where("no_registrasi = ?", noRegistrasi)
+	}
+
+	var sesi []models.SesiKonseling
+	if err := query.Find(&sesi).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mengambil data sesi konseling",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	sembunyikanCatatan(sesi, userID)
+
+	data, err := sanitizeResponseData(c, "sesi_konseling", "", sesi)
+	if err != nil {
+		log.Printf("Failed to sanitize sesi konseling data: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menyiapkan data sesi konseling",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Daftar sesi konseling",
+		Data:    data,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func AdminGetSesiKonselingByID(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	id := c.Params("id")
+	var sesi models.SesiKonseling
+	if err := database.DB.Preload("JanjiTemu").Preload("Konselor").First(&sesi, id).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Sesi konseling tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	if !bolehLihatCatatan(sesi, userID) {
+		sesi.CatatanKonselor = ""
+	}
+
+	data, err := sanitizeResponseData(c, "sesi_konseling", id, sesi)
+	if err != nil {
+		log.Printf("Failed to sanitize sesi konseling data: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menyiapkan data sesi konseling",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Detail sesi konseling",
+		Data:    data,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// AdminCreateSesiKonseling mencatat hasil janji temu yang sudah disetujui. Kehadiran
+// "Hadir" / "Tidak hadir" hanya bisa dicatat setelah waktu janji temu dimulai.
+func AdminCreateSesiKonseling(c *fiber.Ctx) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		return c.Status(http.StatusUnauthorized).JSON(response)
+	}
+
+	janjiTemuID, err := strconv.ParseUint(c.FormValue("janji_temu_id"), 10, 64)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid janji temu ID",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	var janjiTemu models.JanjiTemu
+	if err := database.DB.First(&janjiTemu, janjiTemuID).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Janji temu tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	if janjiTemu.Status != "Disetujui" {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusConflict,
+			Status:  "error",
+			Message: "Sesi hanya bisa dicatat untuk janji temu yang disetujui",
+		}
+		return c.Status(http.StatusConflict).JSON(response)
+	}
+
+	kehadiran := c.FormValue("kehadiran")
+	if !kehadiranValid[kehadiran] {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Kehadiran harus 'Hadir', 'Tidak hadir' atau 'Batal mendadak'",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	if kehadiran != KehadiranBatalMendadak && time.Now().Before(janjiTemu.WaktuDimulai) {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Kehadiran belum bisa dicatat sebelum janji temu dimulai",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	tanggalTindakLanjut, err := parseTanggalTindakLanjut(c.FormValue("tanggal_tindak_lanjut"))
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Format tanggal tindak lanjut harus YYYY-MM-DD",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	konselorID := userID
+	if janjiTemu.KonselorID != nil {
+		konselorID = *janjiTemu.KonselorID
+	}
+	sesi := models.SesiKonseling{
+		JanjiTemuID:         janjiTemu.ID,
+		KonselorID:          konselorID,
+		Kehadiran:           kehadiran,
+		CatatanKonselor:     c.FormValue("catatan_konselor"),
+		TindakLanjut:        c.FormValue("tindak_lanjut"),
+		TanggalTindakLanjut: tanggalTindakLanjut,
+		DicatatOlehID:       userID,
+		NoRegistrasi:        janjiTemu.NoRegistrasi,
+	}
+	if noRegistrasi := c.FormValue("no_registrasi"); noRegistrasi != "" {
+		if err := validasiLaporanSesi(noRegistrasi, janjiTemu); err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Laporan tidak valid untuk janji temu ini",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		sesi.NoRegistrasi = &noRegistrasi
+	}
+
+	var count int64
+	database.DB.Model(&models.SesiKonseling{}).Where("janji_temu_id = ?", janjiTemu.ID).Count(&count)
+	if count > 0 {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusConflict,
+			Status:  "error",
+			Message: "Sesi untuk janji temu ini sudah dicatat",
+		}
+		return c.Status(http.StatusConflict).JSON(response)
+	}
+	if err := database.DB.Create(&sesi).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menyimpan sesi konseling",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusCreated,
+		Status:  "success",
+		Message: "Sesi konseling berhasil dicatat",
+		Data:    sesi,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func AdminUpdateSesiKonseling(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	var sesi models.SesiKonseling
+	if err := database.DB.First(&sesi, c.Params("id")).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Sesi konseling tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	if !bolehLihatCatatan(sesi, userID) {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "Hanya konselor sesi yang dapat mengubah catatan sesi",
+		}
+		return c.Status(http.StatusForbidden).JSON(response)
+	}
+
+	if kehadiran := c.FormValue("kehadiran"); kehadiran != "" {
+		if !kehadiranValid[kehadiran] {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Kehadiran harus 'Hadir', 'Tidak hadir' atau 'Batal mendadak'",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		sesi.Kehadiran = kehadiran
+	}
+	if catatan := c.FormValue("catatan_konselor"); catatan != "" {
+		sesi.CatatanKonselor = catatan
+	}
+	if tindakLanjut := c.FormValue("tindak_lanjut"); tindakLanjut != "" {
+		sesi.TindakLanjut = tindakLanjut
+	}
+	if value := c.FormValue("tanggal_tindak_lanjut"); value != "" {
+		tanggal, err := parseTanggalTindakLanjut(value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Format tanggal tindak lanjut harus YYYY-MM-DD",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		sesi.TanggalTindakLanjut = tanggal
+	}
+	if noRegistrasi := c.FormValue("no_registrasi"); noRegistrasi != "" {
+		var janjiTemu models.JanjiTemu
+		if err := database.DB.First(&janjiTemu, sesi.JanjiTemuID).Error; err != nil || validasiLaporanSesi(noRegistrasi, janjiTemu) != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Laporan tidak valid untuk janji temu ini",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		sesi.NoRegistrasi = &noRegistrasi
+	}
+
+	if err := database.DB.Save(&sesi).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal memperbarui sesi konseling",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Sesi konseling berhasil diperbarui",
+		Data:    sesi,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+type statistikKehadiranKonselor struct {
+	KonselorID    uint    `json:"konselor_id"`
+	NamaKonselor  string  `json:"nama_konselor"`
+	Periode       string  `json:"periode"`
+	Total         int64   `json:"total"`
+	Hadir         int64   `json:"hadir"`
+	TidakHadir    int64   `json:"tidak_hadir"`
+	BatalMendadak int64   `json:"batal_mendadak"`
+	RasioNoShow   float64 `json:"rasio_no_show"`
+}
+
+var formatPeriodeStatistik = map[string]string{
+	"hari":   "%Y-%m-%d",
+	"minggu": "%x-W%v",
+	"bulan":  "%Y-%m",
+	"tahun":  "%Y",
+}
+
+// AdminGetStatistikKehadiranKonseling menghitung rasio no-show (tidak hadir / total
+// sesi tercatat) per konselor dan per periode berdasarkan waktu janji temu.
+func AdminGetStatistikKehadiranKonseling(c *fiber.Ctx) error {
+	periode := c.Query("periode", "bulan")
+	format, ok := formatPeriodeStatistik[periode]
+	if !ok {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Periode harus salah satu dari hari, minggu, bulan atau tahun",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	sampai := time.Now()
+	dari := sampai.AddDate(-1, 0, 0)
+	if value := c.Query("dari"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Format tanggal 'dari' harus YYYY-MM-DD",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		dari = parsed
+	}
+	if value := c.Query("sampai"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Format tanggal 'sampai' harus YYYY-MM-DD",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		sampai = parsed.AddDate(0, 0, 1)
+	}
+
+	query := database.DB.Table("sesi_konselings AS s").
+		Select("s.konselor_id, u.full_name AS nama_konselor, DATE_FORMAT(j.waktu_dimulai, ?) AS periode, "+
+			"COUNT(*) AS total, "+
+			"SUM(CASE WHEN s.kehadiran = ? THEN 1 ELSE 0 END) AS hadir, "+
+			"SUM(CASE WHEN s.kehadiran = ? THEN 1 ELSE 0 END) AS tidak_hadir, "+
+			"SUM(CASE WHEN s.kehadiran = ? THEN 1 ELSE 0 END) AS batal_mendadak",
+			format, KehadiranHadir, KehadiranTidakHadir, KehadiranBatalMendadak).
+		Joins("JOIN janji_temus j ON j.id = s.janji_temu_id").
+		Joins("JOIN users u ON u.id = s.konselor_id").
+		Where("j.waktu_dimulai >= ? AND j.waktu_dimulai < ?", dari, sampai).
+		Group("s.konselor_id, u.full_name, periode").
+		Order("periode asc, s.konselor_id asc")
+	if konselorID := c.Query("konselor_id"); konselorID != "" {
+		query = query.Where("s.konselor_id = ?", konselorID)
+	}
+
+	var statistik []statistikKehadiranKonselor
+	if err := query.Scan(&statistik).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Failed to compute counseling attendance statistics: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menghitung statistik kehadiran",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	for i := range statistik {
+		if statistik[i].Total > 0 {
+			rasio := float64(statistik[i].TidakHadir) / float64(statistik[i].Total) * 100
+			statistik[i].RasioNoShow = math.Round(rasio*100) / 100
+		}
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Statistik kehadiran sesi konseling",
+		Data:    statistik,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -102,7 +102,31 @@ func CreateTrackingLaporan(c *fiber.Ctx) error {
     trackingLaporan.UpdatedAt = now
 
 
-	if err := database.GetGormDBInstance().Create(&trackingLaporan).Error; err != nil {
+	notificationData := JenisTrackingLaporan.Data(
+		PayloadTracking{NoRegistrasi: noRegistrasi, Aksi: "new_tracking"},
+		userID,
+		trackingLaporan.Keterangan,
+		now,
+	)
+
+	// Tracking dan notifikasi (outbox) disimpan dalam satu transaksi.
+	var notifikasi *models.Notification
+	err = database.GetGormDBInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&trackingLaporan).Error; err != nil {
+			return err
+		}
+		var err error
+		notifikasi, err = antrekanNotifikasiTemplate(tx,
+			existingLaporan.UserID,
+			"tracking_dibuat",
+			varsTracking{NoRegistrasi: noRegistrasi, AdaDokumen: len(imageURLs) > 0},
+			notificationData,
+			now,
+		)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to create tracking laporan: %v", err)
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -110,43 +134,8 @@ func CreateTrackingLaporan(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	notifikasiTersimpan(notifikasi)
 
-	var user models.User
-	 db := database.GetGormDBInstance()
-    if err := db.Where("id = ?", existingLaporan.UserID).First(&user).Error; err == nil && user.NotificationToken != "" {
-        notificationData := models.FCMNotificationData{
-            Type:      "tracking_update",
-            ReportID:  noRegistrasi,
-            Status:    "new_tracking",
-			UpdatedBy: userID,
-            UpdatedAt: now.Format(time.RFC3339),
-            Notes:     trackingLaporan.Keterangan,
-            DeepLink:  "laporanku://tracking/" + noRegistrasi,
-        }
-
-        docMessage := "Ada update baru nih!"
-        if len(imageURLs) > 0 {
-            docMessage = "Ada dokumen baru (PDF/Image) yang diunggah untuk laporanmu! 📎"
-        }
-
-        notification, err := NewNotificationFromFCMData(
-            existingLaporan.UserID,
-            "Update Baru pada Laporanmu!",
-            "Halo! Tracking laporan dengan No. "+noRegistrasi+" telah ditambahkan. "+docMessage+" Cek sekarang yuk!",
-            notificationData,
-            now,
-        )
-        if err != nil {
-            log.Printf("Error creating notification: %v", err)
-        } else {
-            if err := db.Create(&notification).Error; err != nil {
-                log.Printf("Failed to store notification: %v", err)
-            }
-            if err := SendFCMNotification(user.NotificationToken, notificationData, *notification); err != nil {
-                log.Printf("Failed to send FCM notification: %v", err)
-            }
-        }
-    }
 	response := helper.ResponseWithData{
 		Code:    http.StatusCreated,
 		Status:  "success",
@@ -280,40 +269,21 @@ func UpdateTrackingLaporan(c *fiber.Ctx) error {
     // Notifikasi untuk user
     var laporan models.Laporan
     if err := db.Where("no_registrasi = ?", trackingLaporan.NoRegistrasi).First(&laporan).Error; err == nil {
-        var user models.User
-        if err := db.Where("id = ?", laporan.UserID).First(&user).Error; err == nil && user.NotificationToken != "" {
-            notificationData := models.FCMNotificationData{
-                Type:      "tracking_update",
-                ReportID:  trackingLaporan.NoRegistrasi,
-                Status:    "updated_tracking",
-                UpdatedAt: now.Format(time.RFC3339),
-                Notes:     trackingLaporan.Keterangan,
-				UpdatedBy: userID,
-                DeepLink:  "laporanku://tracking/" + trackingLaporan.NoRegistrasi,
-            }
-
-            docMessage := "Ada perubahan terbaru pada tracking laporanmu!"
-            if len(imageURLs) > 0 {
-                docMessage = "Dokumen baru (PDF/Image) telah diperbarui untuk laporanmu!"
-            }
+        notificationData := JenisTrackingLaporan.Data(
+            PayloadTracking{NoRegistrasi: trackingLaporan.NoRegistrasi, Aksi: "updated_tracking"},
+            userID,
+            trackingLaporan.Keterangan,
+            now,
+        )
 
-            notification, err := NewNotificationFromFCMData(
-                laporan.UserID,
-                "Tracking Laporanmu Diperbarui!",
-                "Yay! Tracking untuk laporan No. "+trackingLaporan.NoRegistrasi+" telah diperbarui. "+docMessage+" Yuk cek detailnya!",
-                notificationData,
-                now,
-            )
-            if err != nil {
-                log.Printf("Error creating notification: %v", err)
-            } else {
-                if err := db.Create(&notification).Error; err != nil {
-                    log.Printf("Failed to store notification: %v", err)
-                }
-                if err := SendFCMNotification(user.NotificationToken, notificationData, *notification); err != nil {
-                    log.Printf("Failed to send FCM notification: %v", err)
-                }
-            }
+        if err := kirimNotifikasiTemplate(db,
+            laporan.UserID,
+            "tracking_diperbarui",
+            varsTracking{NoRegistrasi: trackingLaporan.NoRegistrasi, AdaDokumen: len(imageURLs) > 0},
+            notificationData,
+            now,
+        ); err != nil {
+            log.Printf("Error creating notification: %v", err)
         }
     }
 
@@ -396,34 +366,21 @@ func DeleteTrackingLaporan(c *fiber.Ctx) error {
     // Notifikasi sebelum delete
     var laporan models.Laporan
     if err := db.Where("no_registrasi = ?", noRegistrasi).First(&laporan).Error; err == nil {
-        var user models.User
-        if err := db.Where("id = ?", laporan.UserID).First(&user).Error; err == nil && user.NotificationToken != "" {
-            notificationData := models.FCMNotificationData{
-                Type:      "tracking_update",
-                ReportID:  noRegistrasi,
-                Status:    "deleted_tracking",
-                UpdatedAt: now.Format(time.RFC3339),
-                Notes:     "Tracking Laporan telah dihapus",
-				UpdatedBy: userID,
-			}
+        notificationData := JenisTrackingLaporan.Data(
+            PayloadTracking{NoRegistrasi: noRegistrasi, Aksi: "deleted_tracking"},
+            userID,
+            "Tracking Laporan telah dihapus",
+            now,
+        )
 
-            notification, err := NewNotificationFromFCMData(
-                laporan.UserID,
-                "Tracking Laporan telah dihapus!",
-                "Halo! Tracking untuk laporan No. "+noRegistrasi+" telah dihapus dari sistem. Ada pertanyaan? Hubungi kami ya!",
-                notificationData,
-                now,
-            )
-            if err != nil {
-                log.Printf("Error creating notification: %v", err)
-            } else {
-                if err := db.Create(&notification).Error; err != nil {
-                    log.Printf("Failed to store notification: %v", err)
-                }
-                if err := SendFCMNotification(user.NotificationToken, notificationData, *notification); err != nil {
-                    log.Printf("Failed to send FCM notification: %v", err)
-                }
-            }
+        if err := kirimNotifikasiTemplate(db,
+            laporan.UserID,
+            "tracking_dihapus",
+            varsTracking{NoRegistrasi: noRegistrasi},
+            notificationData,
+            now,
+        ); err != nil {
+            log.Printf("Error creating notification: %v", err)
         }
     }
 
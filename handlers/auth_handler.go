@@ -40,8 +40,12 @@ func RegisterUser(c *fiber.Ctx) error {
 	if err := c.BodyParser(&user); err != nil {
 		return c.Status(http.StatusBadRequest).JSON(Response{Success: 0, Message: err.Error(), Data: nil})
 	}
-	 // TODO: new Validation for Notification Token required from FRONT END!!
-	if user.FullName == "" || user.Password == "" || user.PhoneNumber == "" || user.Email == "" || user.NotificationToken == ""{
+	// Token perangkat opsional; jika dikirim, perangkat langsung didaftarkan
+	var device models.DeviceInfo
+	if err := c.BodyParser(&device); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(Response{Success: 0, Message: err.Error(), Data: nil})
+	}
+	if user.FullName == "" || user.Password == "" || user.PhoneNumber == "" || user.Email == "" {
 		return c.Status(http.StatusBadRequest).JSON(Response{Success: 0, Message: "Fullname, Password, NoHP, and Email are required fields", Data: nil})
 	}
 
@@ -87,11 +91,14 @@ func RegisterUser(c *fiber.Ctx) error {
 	}
 
 	user.ID = uint(userID)
+	if err := daftarkanPerangkat(database.GetGormDBInstance(), user.ID, device, user.CreatedAt); err != nil {
+		log.Printf("Failed to register device for user %d: %v", user.ID, err)
+	}
 
 	return c.Status(http.StatusOK).JSON(Response{
 		Success: 200,
 		Message: "User registered successfully",
-		Data:    user})
+		Data:    ownResponseData(user)})
 }
 
 func isEmailExists(email string) bool {
@@ -204,7 +211,11 @@ func LoginUser(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(Response{Success: 0, Message: "Failed to fetch user details", Data: nil, UserID: 0})
 	}
 
-	return c.Status(http.StatusOK).JSON(Response{Success: 1, Message: "Anda Berhasil Login", Data: fullUser, Token: token})
+	if err := daftarkanPerangkat(database.GetGormDBInstance(), user.ID, credentials.DeviceInfo, time.Now()); err != nil {
+		log.Printf("Failed to register device for user %d: %v", user.ID, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(Response{Success: 1, Message: "Anda Berhasil Login", Data: ownResponseData(fullUser), Token: token})
 }
 
 func getUserByCredentials(credentials models.LoginCredentials) (models.User, error) {
@@ -130,40 +130,26 @@ func ReportClient(c *fiber.Ctx) error {
 	}
 
 	// Create and send notification
-	notificationData := models.FCMNotificationData{
-		Type:      "admin_notification",
-		ReportID:  reportID,
-		Status:    "resolved",
-		UpdatedBy: uint(userID),
-		UpdatedAt: now.Format(time.RFC3339),
-		Notes:     "Anda telah dilaporkan karena menggunakan kata-kata tidak pantas.",
-		DeepLink:  "laporanku://notifications/" + reportID,
-	}
+	notificationData := JenisPeringatanChat.Data(
+		PayloadModerasiChat{ReportID: reportID, Status: "resolved"},
+		uint(userID),
+		"Anda telah dilaporkan karena menggunakan kata-kata tidak pantas.",
+		now,
+	)
 
-	notification, err := NewNotificationFromFCMData(
+	if err := kirimNotifikasiTemplate(db,
 		uint(req.ClientID),
-		"Peringatan: Pelanggaran Etika Komunikasi",
-		"Anda telah dilaporkan karena menggunakan kata-kata tidak pantas dalam chat.",
+		"chat_peringatan_klien",
+		varsKosong{},
 		notificationData,
 		now,
-	)
-	if err != nil {
+	); err != nil {
 		log.Printf("Error creating notification: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(helper.ResponseWithOutData{
 			Message: "Failed to create notification",
 		})
 	}
 
-	// Save notification to database
-	if err := db.Create(&notification).Error; err != nil {
-		log.Printf("Failed to store notification: %v", err)
-	}
-
-	// Send push notification via FCM
-	if err := SendFCMNotification(client.NotificationToken, notificationData, *notification); err != nil {
-		log.Printf("Failed to send FCM notification: %v", err)
-	}
-
 	return c.Status(fiber.StatusOK).JSON(helper.ResponseWithData{
 		Code:    fiber.StatusOK,
 		Message: "Notification sent to client",
@@ -235,6 +221,18 @@ func UserReportAdmin(c *fiber.Ctx) error {
 		})
 	}
 
+	// Admin yang dikeluhkan tidak ikut menerima peringatan
+	if err := peringatkanAdmin(db, peringatanAdmin{
+		Peristiwa: PeristiwaKeluhanChat,
+		Kunci:     "admin_keluhan_chat",
+		Vars:      varsLaporanAdmin{ReportID: reportID},
+		Referensi: reportID,
+		Aktor:     uint(userID),
+		Kecuali:   uint(req.AdminID),
+	}, now); err != nil {
+		log.Printf("Failed to alert admins about chat complaint: %v", err)
+	}
+
 	// Fetch client for notification token
 	var client models.User
 	if err := db.First(&client, userID).Error; err != nil {
@@ -245,40 +243,25 @@ func UserReportAdmin(c *fiber.Ctx) error {
 	}
 
 	// Create and send notification
-	notificationData := models.FCMNotificationData{
-		Type:      "report_status",
-		ReportID:  reportID,
-		Status:    "pending",
-		UpdatedBy: uint(userID),
-		UpdatedAt: now.Format(time.RFC3339),
-		Notes:     "Terima kasih telah melaporkan admin yang menggunakan kata-kata tidak pantas. Laporan Anda dengan ID " + reportID + " telah diterima dan sedang diverifikasi oleh tim kami.",
-		DeepLink:  "laporanku://reports/" + reportID,
-	}
-	notification, err := NewNotificationFromFCMData(
+	notificationData := JenisKeluhanChat.Data(
+		PayloadModerasiChat{ReportID: reportID, Status: "pending"},
 		uint(userID),
-		"Terima Kasih atas Laporan Anda",
 		"Terima kasih telah melaporkan admin yang menggunakan kata-kata tidak pantas. Laporan Anda dengan ID "+reportID+" telah diterima dan sedang diverifikasi oleh tim kami.",
-		notificationData,
 		now,
 	)
-
-	if err != nil {
+	if err := kirimNotifikasiTemplate(db,
+		uint(userID),
+		"chat_laporan_admin_diterima",
+		varsLaporanAdmin{ReportID: reportID},
+		notificationData,
+		now,
+	); err != nil {
 		log.Printf("Error creating notification: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(helper.ResponseWithOutData{
 			Message: "Failed to create notification",
 		})
 	}
 
-	// Save notification to database
-	if err := db.Create(&notification).Error; err != nil {
-		log.Printf("Failed to store notification: %v", err)
-	}
-
-	// Send push notification via FCM
-	if err := SendFCMNotification(client.NotificationToken, notificationData, *notification); err != nil {
-		log.Printf("Failed to send FCM notification: %v", err)
-	}
-
 	return c.Status(fiber.StatusOK).JSON(helper.ResponseWithData{
 		Message: "Report submitted successfully",
 		Data:    report,
@@ -334,31 +317,20 @@ func SendPushNotification(c *fiber.Ctx) error {
 		req.Data.DeepLink = "laporanku://notifications/general"
 	}
 
-	// Create notification
-	notification, err := NewNotificationFromFCMData(
+	// Simpan notifikasi dan antrekan push ke semua perangkat client
+	if err := kirimNotifikasi(db,
 		uint(req.ClientID),
 		req.Title,
 		req.Body,
 		req.Data,
 		now,
-	)
-	if err != nil {
+	); err != nil {
 		log.Printf("Error creating notification: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(helper.ResponseWithOutData{
 			Message: "Failed to create notification",
 		})
 	}
 
-	// Save notification to database
-	if err := db.Create(&notification).Error; err != nil {
-		log.Printf("Failed to store notification: %v", err)
-	}
-
-	// Send push notification via FCM
-	if err := SendFCMNotification(client.NotificationToken, req.Data, *notification); err != nil {
-		log.Printf("Failed to send FCM notification: %v", err)
-	}
-
 	return c.Status(fiber.StatusOK).JSON(helper.ResponseWithOutData{
 		Message: "Notification sent successfully",
 	})
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const jwtSecretTest = "rahasia-test"
+
+// siapkanDBTest mengganti database.DB dengan SQLite in-memory yang berisi tabel users
+// dan tabel untuk model yang diberikan. database.DB dikembalikan saat test selesai.
+func siapkanDBTest(t *testing.T, tabel ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		// Relasi ke users tidak ikut dimigrasi; tabelnya dibuat manual di bawah.
+		IgnoreRelationshipsWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tabel users dibuat manual karena kolom enum di models.User khusus MySQL.
+	if err := db.Exec(`CREATE TABLE users (id integer PRIMARY KEY, full_name text, username text, role text,
+		photo_profile text, phone_number text, email text, nik integer, tempat_lahir text, tanggal_lahir datetime,
+		jenis_kelamin text, alamat text, password text, created_at datetime, updated_at datetime, notification_token text)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tabel) > 0 {
+		if err := db.AutoMigrate(tabel...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lamaDB := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = lamaDB
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// buatUserLengkapTest menyimpan user dengan identitas lengkap (NIK, telepon, alamat).
+func buatUserLengkapTest(t *testing.T, user models.User) {
+	t.Helper()
+	if err := database.DB.Exec(`INSERT INTO users (id, full_name, username, role, phone_number, email, nik, alamat, password, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		user.ID, user.FullName, user.Username, user.Role, user.PhoneNumber, user.Email, user.NIK, user.Alamat, user.Password, time.Now(), time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tokenTest membuat header Authorization untuk middleware dengan JWT_SECRET_KEY test.
+func tokenTest(t *testing.T, userID uint, role string) string {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", jwtSecretTest)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(jwtSecretTest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + signed
+}
+
+// requestTest menjalankan request ke app dan mengembalikan status serta body JSON.
+// Body yang diawali "{" dikirim sebagai JSON, selain itu sebagai form urlencoded.
+func requestTest(t *testing.T, app *fiber.App, method, path, authorization, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if strings.HasPrefix(body, "{") {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hasil map[string]any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &hasil); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", raw, err)
+		}
+	}
+	return resp.StatusCode, hasil
+}
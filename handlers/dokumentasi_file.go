@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// uploadDokumentasiFiles mengunggah semua file pada fileField ke Cloudinary dan
+// memasangkan caption dari captionField sesuai urutan file.
+func uploadDokumentasiFiles(c *fiber.Ctx, fileField, captionField string) ([]models.DokumentasiFile, error) {
+	form, err := c.MultipartForm()
+	if err != nil || form == nil || len(form.File[fileField]) == 0 {
+		return nil, nil
+	}
+
+	imageURLs, err := helper.UploadMultipleFileToCloudinary(form.File[fileField])
+	if err != nil {
+		return nil, err
+	}
+
+	captions := form.Value[captionField]
+	now := time.Now()
+	dokumentasi := make([]models.DokumentasiFile, 0, len(imageURLs))
+	for i, url := range imageURLs {
+		file := models.DokumentasiFile{URL: url, CreatedAt: now}
+		if i < len(captions) {
+			file.Caption = captions[i]
+		}
+		dokumentasi = append(dokumentasi, file)
+	}
+	return dokumentasi, nil
+}
+
+// hapusUploadDokumentasi menghapus file yang sudah diunggah ke Cloudinary ketika
+// penyimpanan ke database gagal, agar tidak ada file yatim.
+func hapusUploadDokumentasi(dokumentasi []models.DokumentasiFile) {
+	urls := make([]string, 0, len(dokumentasi))
+	for _, file := range dokumentasi {
+		urls = append(urls, file.URL)
+	}
+	if err := helper.DeleteFilesFromCloudinary(urls); err != nil {
+		log.Printf("Failed to clean up dokumentasi uploads: %v", err)
+	}
+}
+
+// dokumentasiIDsToDelete membaca field hapus_dokumentasi, bisa dikirim berulang
+// atau dipisah koma ("1,2,3").
+func dokumentasiIDsToDelete(c *fiber.Ctx) []string {
+	var raw []string
+	if form, err := c.MultipartForm(); err == nil && form != nil {
+		raw = form.Value["hapus_dokumentasi"]
+	} else if value := c.FormValue("hapus_dokumentasi"); value != "" {
+		raw = []string{value}
+	}
+
+	var ids []string
+	for _, value := range raw {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// simpanPerubahanDokumentasi menghapus file yang diminta lalu menambahkan file baru
+// untuk satu pemilik (korban/pelaku).
+func simpanPerubahanDokumentasi(tx *gorm.DB, ownerType string, ownerID uint, hapusIDs []string, baru []models.DokumentasiFile) error {
+	if len(hapusIDs) > 0 {
+		if err := tx.Where("id IN ? AND owner_type = ? AND owner_id = ?", hapusIDs, ownerType, ownerID).
+			Delete(&models.DokumentasiFile{}).Error; err != nil {
+			return err
+		}
+	}
+	for i := range baru {
+		baru[i].OwnerType = ownerType
+		baru[i].OwnerID = ownerID
+	}
+	if len(baru) > 0 {
+		if err := tx.Create(&baru).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"bytes"
+	"context"
+	"errors"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+var errTanpaAlamatEmail = errors.New("user has no email address")
+
+// emailTimeout membatasi satu pengiriman email dari outbox; outboxBatchEmail kali
+// nilai ini harus tetap di bawah outboxLease.
+const emailTimeout = 20 * time.Second
+
+// emailDiizinkan menentukan notifikasi yang juga dikirim lewat email: perubahan
+// status laporan (termasuk tanda terima keluhan chat), keputusan janji temu,
+// peringatan dari admin, dan peringatan untuk admin.
+func emailDiizinkan(data models.FCMNotificationData) bool {
+	switch data.Type {
+	case "report_status", "admin_notification", "admin_alert":
+		return true
+	case "appointment":
+		return data.Status == "approved" || data.Status == "rejected"
+	}
+	return false
+}
+
+type dataEmail struct {
+	Judul     string
+	Isi       string
+	Link      string
+	LabelLink string
+	Penutup   string
+}
+
+var labelEmail = map[string][2]string{
+	BahasaIndonesia: {"Buka Pelita Pena", "Email ini dikirim otomatis oleh Pelita Pena. Atur notifikasi email di menu Preferensi Notifikasi."},
+	BahasaInggris:   {"Open Pelita Pena", "This email was sent automatically by Pelita Pena. Manage email notifications in Notification Preferences."},
+}
+
+var emailTextTemplate = texttemplate.Must(texttemplate.New("text").Parse(`{{.Judul}}
+
+{{.Isi}}
+
+{{.LabelLink}}: {{.Link}}
+
+--
+{{.Penutup}}
+`))
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #222; background: #f5f5f5; padding: 24px;">
+  <div style="max-width: 560px; margin: 0 auto; background: #fff; border-radius: 8px; padding: 24px;">
+    <h2 style="margin-top: 0;">{{.Judul}}</h2>
+    <p style="line-height: 1.5;">{{.Isi}}</p>
+    <p><a href="{{.Link}}" style="display: inline-block; background: #6a1b9a; color: #fff; padding: 10px 18px; border-radius: 4px; text-decoration: none;">{{.LabelLink}}</a></p>
+    <p style="font-size: 12px; color: #888;">{{.Penutup}}</p>
+  </div>
+</body>
+</html>
+`))
+
+// renderEmail menyusun email teks dan HTML dari judul/isi yang sudah dirender.
+func renderEmail(to, bahasa string, data dataEmail) (helper.Email, error) {
+	label, ok := labelEmail[bahasa]
+	if !ok {
+		label = labelEmail[BahasaIndonesia]
+	}
+	if data.LabelLink == "" {
+		data.LabelLink = label[0]
+	}
+	if data.Penutup == "" {
+		data.Penutup = label[1]
+	}
+	if data.Link == "" {
+		data.Link = helper.AppBaseURL()
+	}
+
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, data); err != nil {
+		return helper.Email{}, err
+	}
+	if err := emailHTMLTemplate.Execute(&html, data); err != nil {
+		return helper.Email{}, err
+	}
+	return helper.Email{To: []string{to}, Subject: data.Judul, Text: text.String(), HTML: html.String()}, nil
+}
+
+// kirimEmailOutbox mengirim satu baris outbox saluran email.
+func kirimEmailOutbox(item models.NotificationOutbox, email string, pref models.PreferensiNotifikasi) error {
+	if email == "" {
+		return errTanpaAlamatEmail
+	}
+	data := dataEmail{Judul: item.Title, Isi: item.Body, Link: helper.AppBaseURL() + "/notifikasi"}
+	if pref.ModeSamaran {
+		data.Judul = "Pemberitahuan baru"
+		data.Isi = "Buka aplikasi untuk melihat pemberitahuan Anda."
+	}
+	message, err := renderEmail(email, pref.Bahasa, data)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), emailTimeout)
+	defer cancel()
+	return helper.GetMailer().Send(ctx, message)
+}
+
+// prosesOutboxEmail mengirim baris outbox email satu per satu.
+func prosesOutboxEmail(batch []models.NotificationOutbox, prefs map[uint]models.PreferensiNotifikasi) {
+	userIDs := make([]uint, 0, len(batch))
+	for _, item := range batch {
+		userIDs = append(userIDs, item.UserID)
+	}
+	var users []models.User
+	if err := database.GetGormDBInstance().Select("id", "email").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		for _, item := range batch {
+			catatHasilOutbox(item, err)
+		}
+		return
+	}
+	emails := make(map[uint]string, len(users))
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+	for _, item := range batch {
+		catatHasilOutbox(item, kirimEmailOutbox(item, emails[item.UserID], prefs[item.UserID]))
+	}
+}
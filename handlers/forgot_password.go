@@ -2,13 +2,13 @@ package handlers
 
 import (
 	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
 	"backend-pedika-fiber/models"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"fmt"
 	"net/http"
-	"net/smtp"
-	"os"
+	"net/url"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -70,21 +70,20 @@ func generateResetToken() (string, error) {
 }
 
 func sendResetEmail(email, token string) error {
-	from := os.Getenv("EMAIL_SENDER")
-	password := os.Getenv("EMAIL_PASSWORD")
-	to := []string{email}
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPort := os.Getenv("SMTP_PORT")
-
-	msg := []byte(fmt.Sprintf("Subject: Password Reset\n\nClick the link to reset your password: http://localhost:3000/reset-password?token=%s", token))
-
-	auth := smtp.PlainAuth("", from, password, smtpHost)
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, from, to, msg)
+	link := helper.AppBaseURL() + "/reset-password?token=" + url.QueryEscape(token)
+	message, err := renderEmail(email, BahasaIndonesia, dataEmail{
+		Judul:     "Reset Password",
+		Isi:       "Kami menerima permintaan untuk mengatur ulang password akun Anda. Link berikut berlaku selama 1 jam.",
+		Link:      link,
+		LabelLink: "Atur Ulang Password",
+		Penutup:   "Abaikan email ini jika Anda tidak meminta reset password.",
+	})
 	if err != nil {
 		return err
 	}
-	return nil
+	return helper.GetMailer().Send(context.Background(), message)
 }
+
 func ResetPassword(c *fiber.Ctx) error {
 	var req ResetPasswordRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	limitInboxDefault  = 20
+	limitInboxMaksimal = 100
+)
+
+// filterInbox menerapkan filter query inbox: type (boleh dipisah koma), is_read dan
+// archived (false = default, true = hanya arsip, all = semua).
+func filterInbox(query *gorm.DB, c *fiber.Ctx) *gorm.DB {
+	if tipe := c.Query("type"); tipe != "" {
+		var types []string
+		for _, t := range strings.Split(tipe, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		if len(types) > 0 {
+			query = query.Where("type IN ?", types)
+		}
+	}
+	if isRead := c.Query("is_read"); isRead != "" {
+		if readBool, err := strconv.ParseBool(isRead); err == nil {
+			query = query.Where("is_read = ?", readBool)
+		}
+	}
+	switch c.Query("archived", "false") {
+	case "all":
+	case "true":
+		query = query.Where("archived_at IS NOT NULL")
+	default:
+		query = query.Where("archived_at IS NULL")
+	}
+	return query
+}
+
+// GetInboxNotifikasi mengembalikan notifikasi milik user yang login dengan cursor
+// pagination: kirim next_cursor dari respons sebelumnya sebagai ?cursor= untuk
+// halaman berikutnya. Dipakai oleh admin maupun masyarakat.
+func GetInboxNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(limitInboxDefault)))
+	if err != nil || limit <= 0 {
+		limit = limitInboxDefault
+	}
+	if limit > limitInboxMaksimal {
+		limit = limitInboxMaksimal
+	}
+
+	db := database.GetGormDBInstance()
+	query := filterInbox(db.Where("user_id = ?", userID), c)
+	if cursor := c.Query("cursor"); cursor != "" {
+		cursorID, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid cursor",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		query = query.Where("id < ?", cursorID)
+	}
+
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+	var notifications []models.Notification
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		log.Printf("Failed to retrieve notification inbox: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve notifications",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	hasMore := len(notifications) > limit
+	var nextCursor *uint
+	if hasMore {
+		notifications = notifications[:limit]
+		nextCursor = &notifications[limit-1].ID
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notifications retrieved successfully",
+		Data: fiber.Map{
+			"notifications": notifications,
+			"next_cursor":   nextCursor,
+			"has_more":      hasMore,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetRingkasanInbox mengembalikan jumlah notifikasi belum dibaca, total dan per tipe.
+// Notifikasi yang diarsipkan tidak dihitung.
+func GetRingkasanInbox(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+
+	var rows []struct {
+		Type   string
+		Jumlah int64
+	}
+	db := database.GetGormDBInstance()
+	if err := db.Model(&models.Notification{}).
+		Select("type, COUNT(*) AS jumlah").
+		Where("user_id = ? AND is_read = ? AND archived_at IS NULL", userID, false).
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		log.Printf("Failed to count unread notifications: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve unread notifications count",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	var total int64
+	perTipe := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		perTipe[row.Type] = row.Jumlah
+		total += row.Jumlah
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Unread notifications count retrieved successfully",
+		Data: fiber.Map{
+			"unread_count": total,
+			"per_type":     perTipe,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// permintaanNotifikasiMassal memilih notifikasi untuk operasi massal. IDs, Type dan
+// OnlyRead digabung dengan AND; semuanya tetap dibatasi pada notifikasi milik user.
+type permintaanNotifikasiMassal struct {
+	IDs      []uint `json:"ids"`
+	Type     string `json:"type"`
+	OnlyRead bool   `json:"only_read"`
+}
+
+func (p permintaanNotifikasiMassal) kosong() bool {
+	return len(p.IDs) == 0 && p.Type == "" && !p.OnlyRead
+}
+
+func (p permintaanNotifikasiMassal) terapkan(query *gorm.DB, userID uint) *gorm.DB {
+	query = query.Where("user_id = ?", userID)
+	if len(p.IDs) > 0 {
+		query = query.Where("id IN ?", p.IDs)
+	}
+	if p.Type != "" {
+		query = query.Where("type = ?", p.Type)
+	}
+	if p.OnlyRead {
+		query = query.Where("is_read = ?", true)
+	}
+	return query
+}
+
+// parsePermintaanMassal membaca body operasi massal. Jika wajibFilter, body kosong
+// ditolak supaya satu request tidak menghapus/mengarsipkan seluruh inbox tanpa sengaja.
+func parsePermintaanMassal(c *fiber.Ctx, wajibFilter bool) (permintaanNotifikasiMassal, *helper.ResponseWithOutData) {
+	var req permintaanNotifikasiMassal
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return req, &helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid request body",
+			}
+		}
+	}
+	req.Type = strings.TrimSpace(req.Type)
+	if req.Type == "" {
+		req.Type = strings.TrimSpace(c.Query("type"))
+	}
+	if wajibFilter && req.kosong() {
+		return req, &helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "ids, type atau only_read wajib diisi",
+		}
+	}
+	return req, nil
+}
+
+// ubahNotifikasiMassal menjalankan update pada notifikasi yang dipilih dan membalas
+// dengan jumlah baris yang berubah.
+func ubahNotifikasiMassal(c *fiber.Ctx, wajibFilter bool, tambahan string, updates map[string]interface{}, pesan string) error {
+	userID, _, _ := currentUserClaims(c)
+	req, errResp := parsePermintaanMassal(c, wajibFilter)
+	if errResp != nil {
+		return c.Status(errResp.Code).JSON(errResp)
+	}
+
+	db := database.GetGormDBInstance()
+	query := req.terapkan(db.Model(&models.Notification{}), userID)
+	if tambahan != "" {
+		query = query.Where(tambahan)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		log.Printf("Failed to update notifications: %v", result.Error)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to update notifications",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if result.RowsAffected > 0 {
+		terbitkanJumlahBelumDibaca(db, userID)
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: pesan,
+		Data:    fiber.Map{"updated": result.RowsAffected},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// MarkAllNotificationsAsRead menandai semua notifikasi (atau yang dipilih lewat
+// ids/type) sebagai sudah dibaca.
+func MarkAllNotificationsAsRead(c *fiber.Ctx) error {
+	updates := map[string]interface{}{"is_read": true, "updated_at": time.Now()}
+	return ubahNotifikasiMassal(c, false, "is_read = false", updates, "Notifications marked as read")
+}
+
+// ArsipkanNotifikasi memindahkan notifikasi yang dipilih ke arsip. Notifikasi yang
+// diarsipkan sekaligus dianggap sudah dibaca.
+func ArsipkanNotifikasi(c *fiber.Ctx) error {
+	now := time.Now()
+	updates := map[string]interface{}{"archived_at": now, "is_read": true, "updated_at": now}
+	return ubahNotifikasiMassal(c, true, "archived_at IS NULL", updates, "Notifications archived")
+}
+
+// KeluarkanArsipNotifikasi mengembalikan notifikasi dari arsip ke inbox.
+func KeluarkanArsipNotifikasi(c *fiber.Ctx) error {
+	updates := map[string]interface{}{"archived_at": nil, "updated_at": time.Now()}
+	return ubahNotifikasiMassal(c, true, "archived_at IS NOT NULL", updates, "Notifications restored from archive")
+}
+
+// HapusNotifikasi menghapus permanen notifikasi yang dipilih.
+func HapusNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	req, errResp := parsePermintaanMassal(c, true)
+	if errResp != nil {
+		return c.Status(errResp.Code).JSON(errResp)
+	}
+
+	// Baris outbox yang belum terkirim ikut dibatalkan dalam transaksi yang sama,
+	// supaya notifikasi yang sudah dihapus user tidak tetap muncul di HP/email.
+	db := database.GetGormDBInstance()
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := req.terapkan(tx.Model(&models.Notification{}), userID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.NotificationOutbox{}).
+			Where("notification_id IN ? AND status = ?", ids, OutboxStatusPending).
+			Updates(map[string]interface{}{
+				"status":     OutboxStatusSkipped,
+				"last_error": "notification deleted by user",
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", ids).Delete(&models.Notification{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Printf("Failed to delete notifications: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to delete notifications",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if deleted > 0 {
+		terbitkanJumlahBelumDibaca(db, userID)
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notifications deleted",
+		Data:    fiber.Map{"deleted": deleted},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/middleware"
+	"backend-pedika-fiber/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func siapkanInboxTest(t *testing.T) *fiber.App {
+	t.Helper()
+	siapkanDBTest(t, &models.Notification{})
+	now := time.Now()
+	// User 1: 5 notifikasi (id 1-5, id 4 diarsipkan), user 2: 1 notifikasi (id 6)
+	for i := 1; i <= 5; i++ {
+		tipe := "report_status"
+		if i%2 == 0 {
+			tipe = "appointment"
+		}
+		notification := models.Notification{ID: uint(i), UserID: 1, Type: tipe, Title: fmt.Sprintf("Notifikasi %d", i), Body: "isi", Data: "{}", CreatedAt: now}
+		if i == 4 {
+			notification.ArchivedAt = &now
+		}
+		if err := database.DB.Create(&notification).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.DB.Create(&models.Notification{ID: 6, UserID: 2, Type: "report_status", Title: "Lain", Body: "isi", Data: "{}"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/masyarakat/inbox-notifikasi", middleware.MasyarakatMiddleware, GetInboxNotifikasi)
+	return app
+}
+
+func halamanInboxTest(t *testing.T, app *fiber.App, query string) ([]uint, any, bool) {
+	t.Helper()
+	status, body := requestTest(t, app, "GET", "/masyarakat/inbox-notifikasi"+query, tokenTest(t, 1, "masyarakat"), "")
+	if status != http.StatusOK {
+		t.Fatalf("inbox%s status = %d, want 200: %v", query, status, body)
+	}
+	data := body["Data"].(map[string]any)
+	var ids []uint
+	for _, item := range data["notifications"].([]any) {
+		ids = append(ids, uint(item.(map[string]any)["id"].(float64)))
+	}
+	return ids, data["next_cursor"], data["has_more"].(bool)
+}
+
+func TestInboxCursorPagination(t *testing.T) {
+	app := siapkanInboxTest(t)
+
+	ids, cursor, hasMore := halamanInboxTest(t, app, "?limit=2")
+	if fmt.Sprint(ids) != "[5 3]" || !hasMore || cursor != float64(3) {
+		t.Fatalf("page 1 = %v cursor=%v has_more=%v, want [5 3] cursor=3 has_more=true", ids, cursor, hasMore)
+	}
+	ids, cursor, hasMore = halamanInboxTest(t, app, "?limit=2&cursor=3")
+	if fmt.Sprint(ids) != "[2 1]" || hasMore || cursor != nil {
+		t.Fatalf("page 2 = %v cursor=%v has_more=%v, want [2 1] without next cursor", ids, cursor, hasMore)
+	}
+}
+
+func TestInboxFilterDanCursorTidakValid(t *testing.T) {
+	app := siapkanInboxTest(t)
+
+	if ids, _, _ := halamanInboxTest(t, app, "?type=appointment"); fmt.Sprint(ids) != "[2]" {
+		t.Errorf("type filter = %v, want [2] (archived 4 excluded)", ids)
+	}
+	if ids, _, _ := halamanInboxTest(t, app, "?archived=true"); fmt.Sprint(ids) != "[4]" {
+		t.Errorf("archived filter = %v, want [4]", ids)
+	}
+	if ids, _, _ := halamanInboxTest(t, app, "?archived=all&limit=500"); len(ids) != 5 {
+		t.Errorf("archived=all returned %v, want all 5 of the user's notifications", ids)
+	}
+
+	status, _ := requestTest(t, app, "GET", "/masyarakat/inbox-notifikasi?cursor=abc", tokenTest(t, 1, "masyarakat"), "")
+	if status != http.StatusBadRequest {
+		t.Errorf("invalid cursor status = %d, want 400", status)
+	}
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status janji temu yang masih memakai slot waktu konselor.
+var statusJanjiTemuAktif = []string{"Belum disetujui", "Disetujui", StatusJanjiTemuDiusulkanUlang}
+
+var (
+	errWaktuTidakValid  = errors.New("waktu selesai harus setelah waktu dimulai")
+	errLuarJamKerja     = errors.New("janji temu hanya bisa di hari kerja (Senin-Jumat) pada jam operasional")
+	errJadwalBentrok    = errors.New("konselor sudah memiliki janji temu pada waktu tersebut")
+	errSlotSudahDipesan = errors.New("slot jadwal konselor sudah dipesan")
+)
+
+// jamOperasional membaca JAM_OPERASIONAL_MULAI / JAM_OPERASIONAL_SELESAI (format HH:MM),
+// default 08:00 - 16:00.
+func jamOperasional() (time.Duration, time.Duration) {
+	parse := func(key, fallback string) time.Duration {
+		value := os.Getenv(key)
+		if value == "" {
+			value = fallback
+		}
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			t, _ = time.Parse("15:04", fallback)
+		}
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return parse("JAM_OPERASIONAL_MULAI", "08:00"), parse("JAM_OPERASIONAL_SELESAI", "16:00")
+}
+
+// validasiWaktuJanjiTemu memastikan rentang waktu valid dan berada dalam jam operasional.
+func validasiWaktuJanjiTemu(mulai, selesai time.Time) error {
+	if !selesai.After(mulai) {
+		return errWaktuTidakValid
+	}
+	if mulai.YearDay() != selesai.YearDay() || mulai.Year() != selesai.Year() {
+		return errLuarJamKerja
+	}
+	if mulai.Weekday() == time.Saturday || mulai.Weekday() == time.Sunday {
+		return errLuarJamKerja
+	}
+	buka, tutup := jamOperasional()
+	awalHari := time.Date(mulai.Year(), mulai.Month(), mulai.Day(), 0, 0, 0, 0, mulai.Location())
+	if mulai.Before(awalHari.Add(buka)) || selesai.After(awalHari.Add(tutup)) {
+		return errLuarJamKerja
+	}
+	return nil
+}
+
+// kunciKonselor mengunci baris user konselor (SELECT ... FOR UPDATE) sehingga
+// pemesanan untuk konselor yang sama diproses bergantian.
+func kunciKonselor(tx *gorm.DB, konselorID uint) error {
+	var konselor models.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&konselor, konselorID).Error
+}
+
+// cekBentrokKonselor mengecek apakah konselor sudah punya janji temu aktif yang
+// beririsan dengan rentang waktu. Harus dipanggil setelah kunciKonselor.
+func cekBentrokKonselor(tx *gorm.DB, konselorID uint, mulai, selesai time.Time, kecualiID uint) error {
+	var count int64
+	if err := tx.Model(&models.JanjiTemu{}).
+		Where("konselor_id = ? AND status IN ? AND waktu_dimulai < ? AND waktu_selesai > ? AND id <> ?",
+			konselorID, statusJanjiTemuAktif, selesai, mulai, kecualiID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errJadwalBentrok
+	}
+	return nil
+}
+
+// pesanSlotKonselor mengisi waktu dan konselor janji temu dari slot yang dipilih.
+func pesanSlotKonselor(tx *gorm.DB, janjiTemu *models.JanjiTemu, jadwalID uint) error {
+	var jadwal models.JadwalKonselor
+	if err := tx.First(&jadwal, jadwalID).Error; err != nil {
+		return err
+	}
+	if err := kunciKonselor(tx, jadwal.KonselorID); err != nil {
+		return err
+	}
+	// Slot bisa dihapus admin sebelum kunci didapat; baca ulang setelah terkunci.
+	if err := tx.First(&jadwal, jadwalID).Error; err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.JanjiTemu{}).
+		Where("jadwal_konselor_id = ? AND status IN ? AND id <> ?", jadwal.ID, statusJanjiTemuAktif, janjiTemu.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errSlotSudahDipesan
+	}
+	if err := cekBentrokKonselor(tx, jadwal.KonselorID, jadwal.WaktuMulai, jadwal.WaktuSelesai, janjiTemu.ID); err != nil {
+		return err
+	}
+
+	janjiTemu.WaktuDimulai = jadwal.WaktuMulai
+	janjiTemu.WaktuSelesai = jadwal.WaktuSelesai
+	janjiTemu.KonselorID = &jadwal.KonselorID
+	janjiTemu.JadwalKonselorID = &jadwal.ID
+	return nil
+}
+
+// responseErrorJadwal memetakan error validasi/penjadwalan ke response HTTP.
+func responseErrorJadwal(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	message := "Gagal memproses jadwal janji temu"
+	switch {
+	case errors.Is(err, errWaktuTidakValid), errors.Is(err, errLuarJamKerja):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, errBukanKonselor):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, errJadwalBentrok), errors.Is(err, errSlotSudahDipesan):
+		status = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+		message = "Jadwal konselor tidak ditemukan"
+	}
+	return c.Status(status).JSON(helper.ResponseWithOutData{
+		Code:    status,
+		Status:  "error",
+		Message: message,
+	})
+}
+
+/*=========================== ADMIN: KELOLA SLOT JADWAL KONSELOR =======================*/
+
+func AdminGetJadwalKonselor(c *fiber.Ctx) error {
+	query := database.DB.Order("waktu_mulai asc")
+	if konselorID := c.Query("konselor_id"); konselorID != "" {
+		query = query.Where("konselor_id = ?", konselorID)
+	}
+	if c.QueryBool("mendatang", true) {
+		query = query.Where("waktu_mulai > ?", time.Now())
+	}
+
+	var jadwal []models.JadwalKonselor
+	if err := query.Find(&jadwal).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve jadwal konselor",
+		})
+	}
+	data, err := toJadwalKonselorPublik(database.DB, jadwal)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to prepare jadwal konselor",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "List of jadwal konselor",
+		Data:    data,
+	})
+}
+
+func AdminCreateJadwalKonselor(c *fiber.Ctx) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+
+	konselorID := userID
+	if value := c.FormValue("konselor_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid konselor ID",
+			})
+		}
+		konselorID = uint(id)
+	}
+
+	waktuMulai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_mulai"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid format for start time",
+		})
+	}
+	waktuSelesai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_selesai"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid format for end time",
+		})
+	}
+	if err := validasiWaktuJanjiTemu(waktuMulai, waktuSelesai); err != nil {
+		return responseErrorJadwal(c, err)
+	}
+
+	jadwal := models.JadwalKonselor{
+		KonselorID:   konselorID,
+		WaktuMulai:   waktuMulai,
+		WaktuSelesai: waktuSelesai,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var konselor models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND role = ?", konselorID, "admin").First(&konselor).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.JadwalKonselor{}).
+			Where("konselor_id = ? AND waktu_mulai < ? AND waktu_selesai > ?", konselorID, waktuSelesai, waktuMulai).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errJadwalBentrok
+		}
+		return tx.Create(&jadwal).Error
+	})
+	if err != nil {
+		return responseErrorJadwal(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(helper.ResponseWithData{
+		Code:    http.StatusCreated,
+		Status:  "success",
+		Message: "Jadwal konselor created successfully",
+		Data:    jadwal,
+	})
+}
+
+func AdminDeleteJadwalKonselor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var jadwal models.JadwalKonselor
+	if err := database.DB.First(&jadwal, id).Error; err != nil {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Jadwal konselor not found",
+		})
+	}
+
+	// Kunci konselor yang sama dengan pemesanan slot, agar slot tidak terhapus
+	// di antara pengecekan dan pemesanan yang berjalan bersamaan.
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := kunciKonselor(tx, jadwal.KonselorID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.JanjiTemu{}).
+			Where("jadwal_konselor_id = ? AND status IN ?", jadwal.ID, statusJanjiTemuAktif).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errSlotSudahDipesan
+		}
+		return tx.Delete(&jadwal).Error
+	})
+	if errors.Is(err, errSlotSudahDipesan) {
+		return c.Status(http.StatusConflict).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusConflict,
+			Status:  "error",
+			Message: "Jadwal sudah dipesan, batalkan janji temunya terlebih dahulu",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to delete jadwal konselor",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Jadwal konselor deleted successfully",
+	})
+}
+
+/*=========================== MASYARAKAT: LIHAT SLOT YANG MASIH KOSONG =======================*/
+
+func GetJadwalKonselorTersedia(c *fiber.Ctx) error {
+	query := database.DB.
+		Where("waktu_mulai > ?", time.Now()).
+		Where("NOT EXISTS (SELECT 1 FROM janji_temus WHERE janji_temus.jadwal_konselor_id = jadwal_konselors.id AND janji_temus.status IN ?)", statusJanjiTemuAktif).
+		Order("waktu_mulai asc")
+
+	if tanggal := c.Query("tanggal"); tanggal != "" {
+		hari, err := time.Parse("2006-01-02", tanggal)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid format for tanggal, use yyyy-MM-dd",
+			})
+		}
+		query = query.Where("waktu_mulai >= ? AND waktu_mulai < ?", hari, hari.AddDate(0, 0, 1))
+	}
+	if konselorID := c.Query("konselor_id"); konselorID != "" {
+		query = query.Where("konselor_id = ?", konselorID)
+	}
+
+	var jadwal []models.JadwalKonselor
+	if err := query.Find(&jadwal).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve jadwal konselor",
+		})
+	}
+	data, err := toJadwalKonselorPublik(database.DB, jadwal)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to prepare jadwal konselor",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "List of available jadwal konselor",
+		Data:    data,
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/middleware"
+	"backend-pedika-fiber/models"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+func siapkanJadwalKonselorTest(t *testing.T) *fiber.App {
+	t.Helper()
+	siapkanDBTest(t, &models.JanjiTemu{}, &models.JadwalKonselor{}, &models.Notification{}, &models.NotificationOutbox{}, &models.PreferensiNotifikasi{})
+	buatUserLengkapTest(t, models.User{ID: 1, FullName: "Warga", Username: "warga", Role: "masyarakat", PhoneNumber: "0811", Email: "warga@example.com"})
+	buatUserLengkapTest(t, models.User{ID: 2, FullName: "Konselor", Username: "konselor", Role: "admin", PhoneNumber: "0812", Email: "konselor@example.com"})
+	buatUserLengkapTest(t, models.User{ID: 3, FullName: "Warga Lain", Username: "lain", Role: "masyarakat", PhoneNumber: "0813", Email: "lain@example.com"})
+
+	app := fiber.New()
+	app.Post("/masyarakat/create-janjitemu", middleware.MasyarakatMiddleware, MasyarakatCreateJanjiTemu)
+	app.Delete("/admin/jadwal-konselor/:id", middleware.AdminMiddleware, AdminDeleteJadwalKonselor)
+	return app
+}
+
+func buatJanjiTemuTest(t *testing.T, janjiTemu models.JanjiTemu) models.JanjiTemu {
+	t.Helper()
+	if err := database.DB.Omit(clause.Associations).Create(&janjiTemu).Error; err != nil {
+		t.Fatal(err)
+	}
+	return janjiTemu
+}
+
+func TestCekBentrokKonselor(t *testing.T) {
+	siapkanJadwalKonselorTest(t)
+	konselor := uint(2)
+	mulai := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	aktif := buatJanjiTemuTest(t, models.JanjiTemu{UserID: 1, KonselorID: &konselor, Status: "Disetujui",
+		WaktuDimulai: mulai, WaktuSelesai: mulai.Add(time.Hour)})
+	buatJanjiTemuTest(t, models.JanjiTemu{UserID: 3, KonselorID: &konselor, Status: "Dibatalkan",
+		WaktuDimulai: mulai.Add(2 * time.Hour), WaktuSelesai: mulai.Add(3 * time.Hour)})
+
+	kasus := []struct {
+		nama           string
+		mulai, selesai time.Time
+		kecuali        uint
+		bentrok        bool
+	}{
+		{"beririsan", mulai.Add(30 * time.Minute), mulai.Add(90 * time.Minute), 0, true},
+		{"di dalam", mulai.Add(15 * time.Minute), mulai.Add(45 * time.Minute), 0, true},
+		{"bersebelahan", mulai.Add(time.Hour), mulai.Add(2 * time.Hour), 0, false},
+		{"janji temu dibatalkan", mulai.Add(2 * time.Hour), mulai.Add(3 * time.Hour), 0, false},
+		{"janji temu sendiri", mulai, mulai.Add(time.Hour), aktif.ID, false},
+	}
+	for _, k := range kasus {
+		err := cekBentrokKonselor(database.DB, konselor, k.mulai, k.selesai, k.kecuali)
+		if got := errors.Is(err, errJadwalBentrok); got != k.bentrok {
+			t.Errorf("%s: bentrok = %v (err %v), want %v", k.nama, got, err, k.bentrok)
+		}
+	}
+}
+
+func TestPesanSlotKonselorHanyaSekali(t *testing.T) {
+	app := siapkanJadwalKonselorTest(t)
+	mulai := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	jadwal := models.JadwalKonselor{KonselorID: 2, WaktuMulai: mulai, WaktuSelesai: mulai.Add(time.Hour)}
+	if err := database.DB.Omit(clause.Associations).Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+	form := "keperluan_konsultasi=Konsultasi&jadwal_konselor_id=" + formatID(jadwal.ID)
+
+	status, body := requestTest(t, app, "POST", "/masyarakat/create-janjitemu", tokenTest(t, 1, "masyarakat"), form)
+	if status != http.StatusCreated {
+		t.Fatalf("first booking status = %d, want 201: %v", status, body)
+	}
+	data := body["Data"].(map[string]any)
+	if data["konselor_id"] != float64(2) || data["jadwal_konselor_id"] != float64(jadwal.ID) {
+		t.Errorf("booking not linked to slot: %v", data)
+	}
+
+	status, body = requestTest(t, app, "POST", "/masyarakat/create-janjitemu", tokenTest(t, 3, "masyarakat"), form)
+	if status != http.StatusConflict {
+		t.Errorf("second booking status = %d, want 409: %v", status, body)
+	}
+
+	// Slot yang sudah dipesan tidak bisa dihapus
+	status, _ = requestTest(t, app, "DELETE", "/admin/jadwal-konselor/"+formatID(jadwal.ID), tokenTest(t, 2, "admin"), "")
+	if status != http.StatusConflict {
+		t.Errorf("delete booked slot status = %d, want 409", status)
+	}
+}
+
+func TestHapusSlotKosong(t *testing.T) {
+	app := siapkanJadwalKonselorTest(t)
+	mulai := time.Date(2030, 1, 7, 13, 0, 0, 0, time.UTC)
+	jadwal := models.JadwalKonselor{KonselorID: 2, WaktuMulai: mulai, WaktuSelesai: mulai.Add(time.Hour)}
+	if err := database.DB.Omit(clause.Associations).Create(&jadwal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := requestTest(t, app, "DELETE", "/admin/jadwal-konselor/"+formatID(jadwal.ID), tokenTest(t, 2, "admin"), "")
+	if status != http.StatusOK {
+		t.Fatalf("delete status = %d, want 200: %v", status, body)
+	}
+	status, _ = requestTest(t, app, "POST", "/masyarakat/create-janjitemu", tokenTest(t, 1, "masyarakat"),
+		"keperluan_konsultasi=Konsultasi&jadwal_konselor_id="+formatID(jadwal.ID))
+	if status != http.StatusNotFound {
+		t.Errorf("booking deleted slot status = %d, want 404", status)
+	}
+}
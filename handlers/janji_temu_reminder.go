@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/models"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// reminderOffsets membaca JANJI_TEMU_REMINDER_OFFSETS (contoh "24h,1h"), diurutkan dari yang terbesar.
+func reminderOffsets() []time.Duration {
+	value := os.Getenv("JANJI_TEMU_REMINDER_OFFSETS")
+	if value == "" {
+		value = "24h,1h"
+	}
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || offset <= 0 {
+			log.Printf("Ignoring invalid reminder offset %q", part)
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	return offsets
+}
+
+// StartJanjiTemuReminderScheduler menjalankan pengecekan pengingat janji temu setiap menit.
+func StartJanjiTemuReminderScheduler() {
+	offsets := reminderOffsets()
+	if len(offsets) == 0 {
+		log.Println("Janji temu reminder scheduler disabled: no offsets configured")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			kirimPengingatJanjiTemu(time.Now(), offsets)
+			<-ticker.C
+		}
+	}()
+	log.Printf("Janji temu reminder scheduler started with offsets %v", offsets)
+}
+
+func kirimPengingatJanjiTemu(now time.Time, offsets []time.Duration) {
+	db := database.GetGormDBInstance()
+
+	for i, offset := range offsets {
+		// Jendela offset ini berakhir di offset berikutnya yang lebih kecil, supaya
+		// janji temu yang dibuat mendadak tidak menerima beberapa pengingat sekaligus.
+		batasBawah := time.Duration(0)
+		if i+1 < len(offsets) {
+			batasBawah = offsets[i+1]
+		}
+
+		var janjiTemus []models.JanjiTemu
+		if err := db.Where("status = ? AND waktu_dimulai > ? AND waktu_dimulai <= ?",
+			"Disetujui", now.Add(batasBawah), now.Add(offset)).
+			Find(&janjiTemus).Error; err != nil {
+			log.Printf("Failed to query janji temu for reminders: %v", err)
+			return
+		}
+
+		for _, janjiTemu := range janjiTemus {
+			for _, penerimaID := range penerimaPengingat(janjiTemu) {
+				kirimSatuPengingat(janjiTemu, penerimaID, offset, now)
+			}
+		}
+	}
+}
+
+// penerimaPengingat: masyarakat pemohon dan staf yang menangani janji temu.
+func penerimaPengingat(janjiTemu models.JanjiTemu) []uint {
+	penerima := []uint{janjiTemu.UserID}
+	staffID := janjiTemu.KonselorID
+	if staffID == nil {
+		staffID = janjiTemu.UserIDTolakSetujui
+	}
+	if staffID != nil && *staffID != janjiTemu.UserID {
+		penerima = append(penerima, *staffID)
+	}
+	return penerima
+}
+
+func kirimSatuPengingat(janjiTemu models.JanjiTemu, penerimaID uint, offset time.Duration, now time.Time) {
+	db := database.GetGormDBInstance()
+
+	// Catat dulu; jika baris sudah ada (unique index) berarti pengingat ini sudah pernah dikirim.
+	pengingat := models.PengingatJanjiTemu{
+		JanjiTemuID: janjiTemu.ID,
+		UserID:      penerimaID,
+		OffsetMenit: int(offset / time.Minute),
+		CreatedAt:   now,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&pengingat)
+	if result.Error != nil {
+		log.Printf("Failed to record janji temu reminder: %v", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	sisaWaktu := janjiTemu.WaktuDimulai.Sub(now).Round(time.Minute)
+	notificationData := JenisJanjiTemu.Data(PayloadJanjiTemu{JanjiTemuID: janjiTemu.ID, Status: "reminder"}, 0, "Pengingat janji temu", now)
+	// Pengingat yang dekat dengan jadwal tidak boleh tertahan jam tenang
+	notificationData.Urgent = offset <= 2*time.Hour
+
+	vars := varsJanjiTemu{WaktuDimulai: janjiTemu.WaktuDimulai, WaktuSelesai: janjiTemu.WaktuSelesai, SisaWaktu: sisaWaktu}
+	if err := kirimNotifikasiTemplate(db, penerimaID, "janji_temu_pengingat", vars, notificationData, now); err != nil {
+		log.Printf("Failed to send janji temu reminder: %v", err)
+	}
+}
+
+func formatSisaWaktu(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%d hari", int(d.Hours()/24))
+	}
+	if d >= time.Hour {
+		return fmt.Sprintf("%d jam", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d menit", int(d.Minutes()))
+}
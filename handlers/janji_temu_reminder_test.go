@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/models"
+	"testing"
+	"time"
+)
+
+func jumlahPengingatTest(t *testing.T, janjiTemuID uint) int64 {
+	t.Helper()
+	var total int64
+	if err := database.DB.Model(&models.PengingatJanjiTemu{}).Where("janji_temu_id = ?", janjiTemuID).Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func jumlahNotifikasiTest(t *testing.T, userID uint) int64 {
+	t.Helper()
+	var total int64
+	if err := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestPengingatJanjiTemuDikirimSekali(t *testing.T) {
+	siapkanOutboxTest(t)
+	if err := database.DB.AutoMigrate(&models.JanjiTemu{}, &models.PengingatJanjiTemu{}, &models.TemplateNotifikasi{}); err != nil {
+		t.Fatal(err)
+	}
+	buatUserTest(t, 1, "warga@example.com", "", "", "token-1")
+	buatUserTest(t, 2, "konselor@example.com", "", "", "")
+	now := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	konselor := uint(2)
+	janjiTemu := buatJanjiTemuTest(t, models.JanjiTemu{UserID: 1, KonselorID: &konselor, Status: "Disetujui",
+		WaktuDimulai: now.Add(50 * time.Minute), WaktuSelesai: now.Add(110 * time.Minute)})
+	offsets := []time.Duration{24 * time.Hour, time.Hour}
+
+	// Scheduler berjalan tiap menit: putaran berikutnya tidak boleh mengirim ulang
+	for i := 0; i < 3; i++ {
+		kirimPengingatJanjiTemu(now.Add(time.Duration(i)*time.Minute), offsets)
+	}
+
+	if got := jumlahPengingatTest(t, janjiTemu.ID); got != 2 {
+		t.Errorf("reminder rows = %d, want 2 (pemohon dan konselor)", got)
+	}
+	var pengingat models.PengingatJanjiTemu
+	if err := database.DB.Where("janji_temu_id = ? AND user_id = ?", janjiTemu.ID, 1).First(&pengingat).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pengingat.OffsetMenit != 60 {
+		t.Errorf("offset = %d, want 60: a booking inside the 1h window must not get the 24h reminder", pengingat.OffsetMenit)
+	}
+	for _, userID := range []uint{1, 2} {
+		if got := jumlahNotifikasiTest(t, userID); got != 1 {
+			t.Errorf("notifications for user %d = %d, want 1", userID, got)
+		}
+	}
+}
+
+func TestPengingatJanjiTemuPerOffset(t *testing.T) {
+	siapkanOutboxTest(t)
+	if err := database.DB.AutoMigrate(&models.JanjiTemu{}, &models.PengingatJanjiTemu{}, &models.TemplateNotifikasi{}); err != nil {
+		t.Fatal(err)
+	}
+	buatUserTest(t, 1, "warga@example.com", "", "", "token-1")
+	now := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	janjiTemu := buatJanjiTemuTest(t, models.JanjiTemu{UserID: 1, Status: "Disetujui",
+		WaktuDimulai: now.Add(20 * time.Hour), WaktuSelesai: now.Add(21 * time.Hour)})
+	buatJanjiTemuTest(t, models.JanjiTemu{UserID: 1, Status: "Dibatalkan",
+		WaktuDimulai: now.Add(20 * time.Hour), WaktuSelesai: now.Add(21 * time.Hour)})
+	offsets := []time.Duration{24 * time.Hour, time.Hour}
+
+	kirimPengingatJanjiTemu(now, offsets)
+	kirimPengingatJanjiTemu(now.Add(19*time.Hour+30*time.Minute), offsets)
+	kirimPengingatJanjiTemu(now.Add(19*time.Hour+31*time.Minute), offsets)
+
+	if got := jumlahPengingatTest(t, janjiTemu.ID); got != 2 {
+		t.Errorf("reminder rows = %d, want 2 (24h dan 1h)", got)
+	}
+	if got := jumlahNotifikasiTest(t, 1); got != 2 {
+		t.Errorf("notifications = %d, want 2", got)
+	}
+}
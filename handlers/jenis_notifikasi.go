@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Payload bertipe per jenis notifikasi. Payload dikirim sebagai JSON di field
+// "payload" pada data FCM dan notifikasi tersimpan; field lama reportId/status tetap
+// diisi untuk aplikasi versi lama.
+
+type PayloadLaporan struct {
+	NoRegistrasi string `json:"noRegistrasi"`
+	Status       string `json:"status"` // in_progress, completed
+}
+
+type PayloadTracking struct {
+	NoRegistrasi string `json:"noRegistrasi"`
+	Aksi         string `json:"aksi"` // new_tracking, updated_tracking, deleted_tracking
+}
+
+type PayloadJanjiTemu struct {
+	JanjiTemuID uint   `json:"janjiTemuId"`
+	Status      string `json:"status"` // approved, rejected, reminder, reschedule_*
+}
+
+type PayloadModerasiChat struct {
+	ReportID string `json:"reportId"` // ID laporan moderasi (report_admins), bukan no registrasi
+	Status   string `json:"status"`
+}
+
+type PayloadPengumuman struct {
+	KampanyeID uint   `json:"kampanyeId"`
+	Tautan     string `json:"tautan,omitempty"`
+}
+
+type PayloadPeringatanAdmin struct {
+	Peristiwa string `json:"peristiwa"` // laporan_baru, laporan_dibatalkan, janji_temu_baru, keluhan_chat
+	Referensi string `json:"referensi"` // no registrasi, ID janji temu atau ID laporan moderasi
+}
+
+// payloadNotifikasi mengisi field lama FCMNotificationData dari payload bertipe.
+type payloadNotifikasi interface {
+	referensi() string
+	status() string
+}
+
+func (p PayloadLaporan) referensi() string      { return p.NoRegistrasi }
+func (p PayloadLaporan) status() string         { return p.Status }
+func (p PayloadTracking) referensi() string     { return p.NoRegistrasi }
+func (p PayloadTracking) status() string        { return p.Aksi }
+func (p PayloadJanjiTemu) referensi() string    { return formatID(p.JanjiTemuID) }
+func (p PayloadJanjiTemu) status() string       { return p.Status }
+func (p PayloadModerasiChat) referensi() string { return p.ReportID }
+func (p PayloadModerasiChat) status() string    { return p.Status }
+func (p PayloadPengumuman) referensi() string {
+	if p.KampanyeID == 0 {
+		return ""
+	}
+	return formatID(p.KampanyeID)
+}
+func (p PayloadPengumuman) status() string         { return "broadcast" }
+func (p PayloadPeringatanAdmin) referensi() string { return p.Referensi }
+func (p PayloadPeringatanAdmin) status() string    { return p.Peristiwa }
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// JenisNotifikasi adalah satu jenis notifikasi di registry: tipe, versi payload dan
+// pembentuk deep link. Versi dinaikkan setiap kali field payload berubah tidak
+// kompatibel.
+type JenisNotifikasi[P payloadNotifikasi] struct {
+	// Nama adalah kunci jenis di registry (dikirim sebagai "kind"); kosong = Tipe.
+	// Tipe adalah "type" lama yang sudah dikenal aplikasi dan tidak boleh diubah
+	// untuk peristiwa yang sudah ada.
+	Nama      string
+	Tipe      string
+	Versi     int
+	Deskripsi string
+	// PolaDeepLink hanya untuk dokumentasi skema; DeepLink yang dipakai saat kirim.
+	PolaDeepLink string
+	DeepLink     func(P) string
+}
+
+// Data menyusun FCMNotificationData dari payload bertipe.
+func (j JenisNotifikasi[P]) Data(payload P, updatedBy uint, notes string, now time.Time) models.FCMNotificationData {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode %s payload: %v", j.nama(), err)
+	}
+	return models.FCMNotificationData{
+		Type:      j.Tipe,
+		Kind:      j.nama(),
+		Version:   j.Versi,
+		Payload:   string(raw),
+		ReportID:  payload.referensi(),
+		Status:    payload.status(),
+		UpdatedBy: updatedBy,
+		UpdatedAt: now.Format(time.RFC3339),
+		Notes:     notes,
+		DeepLink:  j.DeepLink(payload),
+	}
+}
+
+func (j JenisNotifikasi[P]) nama() string {
+	if j.Nama != "" {
+		return j.Nama
+	}
+	return j.Tipe
+}
+
+type skemaJenisNotifikasi struct {
+	Nama      string         `json:"kind"`
+	Tipe      string         `json:"type"`
+	Versi     int            `json:"version"`
+	Deskripsi string         `json:"description"`
+	DeepLink  string         `json:"deep_link"`
+	Payload   map[string]any `json:"payload"`
+}
+
+var registryJenisNotifikasi = map[string]skemaJenisNotifikasi{}
+
+func daftarJenis[P payloadNotifikasi](j JenisNotifikasi[P]) JenisNotifikasi[P] {
+	if _, ada := registryJenisNotifikasi[j.nama()]; ada {
+		panic("duplicate notification kind " + j.nama())
+	}
+	var payload P
+	registryJenisNotifikasi[j.nama()] = skemaJenisNotifikasi{
+		Nama:      j.nama(),
+		Tipe:      j.Tipe,
+		Versi:     j.Versi,
+		Deskripsi: j.Deskripsi,
+		DeepLink:  j.PolaDeepLink,
+		Payload:   skemaJSON(reflect.TypeOf(payload)),
+	}
+	return j
+}
+
+var (
+	JenisStatusLaporan = daftarJenis(JenisNotifikasi[PayloadLaporan]{
+		Tipe: "report_status", Versi: 1,
+		Deskripsi:    "Perubahan status laporan kekerasan",
+		PolaDeepLink: "laporanku://reports/{noRegistrasi}",
+		DeepLink:     func(p PayloadLaporan) string { return "laporanku://reports/" + p.NoRegistrasi },
+	})
+	JenisTrackingLaporan = daftarJenis(JenisNotifikasi[PayloadTracking]{
+		Tipe: "tracking_update", Versi: 1,
+		Deskripsi:    "Tracking laporan ditambah, diubah atau dihapus",
+		PolaDeepLink: "laporanku://tracking/{noRegistrasi}",
+		DeepLink:     func(p PayloadTracking) string { return "laporanku://tracking/" + p.NoRegistrasi },
+	})
+	JenisJanjiTemu = daftarJenis(JenisNotifikasi[PayloadJanjiTemu]{
+		Tipe: "appointment", Versi: 1,
+		Deskripsi:    "Keputusan, pengingat dan usulan jadwal janji temu",
+		PolaDeepLink: "laporanku://appointments/{janjiTemuId}",
+		DeepLink:     func(p PayloadJanjiTemu) string { return "laporanku://appointments/" + formatID(p.JanjiTemuID) },
+	})
+	JenisPeringatanChat = daftarJenis(JenisNotifikasi[PayloadModerasiChat]{
+		Tipe: "admin_notification", Versi: 1,
+		Deskripsi:    "Peringatan admin kepada pengguna karena kata-kata tidak pantas di chat",
+		PolaDeepLink: "laporanku://notifications/{reportId}",
+		DeepLink:     func(p PayloadModerasiChat) string { return "laporanku://notifications/" + p.ReportID },
+	})
+	// Tanda terima keluhan sejak awal dikirim sebagai report_status dengan deep link
+	// ke reports; type dan deep link dipertahankan agar aplikasi lama tetap bekerja,
+	// jenisnya dibedakan lewat "kind".
+	JenisKeluhanChat = daftarJenis(JenisNotifikasi[PayloadModerasiChat]{
+		Nama: "client_report", Tipe: "report_status", Versi: 1,
+		Deskripsi:    "Tanda terima keluhan pengguna terhadap admin di chat",
+		PolaDeepLink: "laporanku://reports/{reportId}",
+		DeepLink:     func(p PayloadModerasiChat) string { return "laporanku://reports/" + p.ReportID },
+	})
+	JenisPengumuman = daftarJenis(JenisNotifikasi[PayloadPengumuman]{
+		Tipe: "announcement", Versi: 1,
+		Deskripsi:    "Pengumuman/kampanye dari admin",
+		PolaDeepLink: "{tautan} atau laporanku://notifications",
+		DeepLink: func(p PayloadPengumuman) string {
+			if p.Tautan != "" {
+				return p.Tautan
+			}
+			return "laporanku://notifications"
+		},
+	})
+	JenisPeringatanAdmin = daftarJenis(JenisNotifikasi[PayloadPeringatanAdmin]{
+		Tipe: "admin_alert", Versi: 1,
+		Deskripsi:    "Peringatan untuk admin tentang laporan, janji temu dan keluhan baru",
+		PolaDeepLink: "laporanku://reports/{referensi} | laporanku://appointments/{referensi} | laporanku://notifications/{referensi}",
+		DeepLink: func(p PayloadPeringatanAdmin) string {
+			switch p.Peristiwa {
+			case PeristiwaLaporanBaru, PeristiwaLaporanDibatalkan:
+				return "laporanku://reports/" + p.Referensi
+			case PeristiwaJanjiTemuBaru:
+				return "laporanku://appointments/" + p.Referensi
+			}
+			return "laporanku://notifications/" + p.Referensi
+		},
+	})
+)
+
+// skemaJSON membentuk JSON Schema sederhana dari struct payload.
+func skemaJSON(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		nama, opsi, _ := strings.Cut(tag, ",")
+		if nama == "" || nama == "-" {
+			continue
+		}
+		properties[nama] = map[string]any{"type": tipeJSON(field.Type)}
+		if !strings.Contains(opsi, "omitempty") {
+			required = append(required, nama)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": true,
+	}
+}
+
+func tipeJSON(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
+
+// amplopNotifikasi adalah key data FCM yang selalu dikirim (semua bernilai string).
+var amplopNotifikasi = map[string]string{
+	"type":      "tipe lama yang dikenal semua versi aplikasi",
+	"kind":      "jenis notifikasi di registry, lihat daftar kinds",
+	"version":   "versi payload untuk jenis tersebut",
+	"payload":   "JSON payload bertipe sesuai jenis",
+	"deepLink":  "tujuan saat notifikasi dibuka",
+	"updatedBy": "ID user yang memicu notifikasi",
+	"updatedAt": "waktu RFC3339",
+	"notes":     "teks tambahan",
+	"imageUrl":  "opsional",
+	"reportId":  "usang: gunakan payload",
+	"status":    "usang: gunakan payload",
+}
+
+// GetSkemaNotifikasi mempublikasikan registry jenis notifikasi agar aplikasi bisa
+// memvalidasi payload yang diterima.
+func GetSkemaNotifikasi(c *fiber.Ctx) error {
+	kinds := make([]skemaJenisNotifikasi, 0, len(registryJenisNotifikasi))
+	for _, skema := range registryJenisNotifikasi {
+		kinds = append(kinds, skema)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Nama < kinds[j].Nama })
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification schema retrieved successfully",
+		Data: fiber.Map{
+			"envelope": amplopNotifikasi,
+			"kinds":    kinds,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func appTimezone() string {
+	if tz := os.Getenv("APP_TIMEZONE"); tz != "" {
+		return tz
+	}
+	return "Asia/Jakarta"
+}
+
+func janjiTemuICalStatus(status string) string {
+	switch status {
+	case "Disetujui":
+		return "CONFIRMED"
+	case "Dibatalkan", "Ditolak":
+		return "CANCELLED"
+	default:
+		return "TENTATIVE"
+	}
+}
+
+func janjiTemuToICalEvent(janjiTemu models.JanjiTemu) helper.ICalEvent {
+	summary := "Janji Temu Konsultasi"
+	if janjiTemu.Status == "Dibatalkan" || janjiTemu.Status == "Ditolak" {
+		summary += " (" + janjiTemu.Status + ")"
+	}
+	return helper.ICalEvent{
+		UID:         fmt.Sprintf("janjitemu-%d@pelitapena", janjiTemu.ID),
+		Summary:     summary,
+		Description: janjiTemu.KeperluanKonsultasi,
+		Start:       janjiTemu.WaktuDimulai,
+		End:         janjiTemu.WaktuSelesai,
+		Status:      janjiTemuICalStatus(janjiTemu.Status),
+		Sequence:    int64(janjiTemu.Revisi),
+		Stamp:       janjiTemu.UpdatedAt,
+	}
+}
+
+// eventToICalEvent: event yang sudah dihapus dikirim sebagai CANCELLED dengan
+// SEQUENCE dinaikkan agar kalender pelanggan ikut menghapusnya.
+func eventToICalEvent(event models.Event) helper.ICalEvent {
+	ical := helper.ICalEvent{
+		UID:         fmt.Sprintf("event-%d@pelitapena", event.ID),
+		Summary:     event.NamaEvent,
+		Description: event.DeskripsiEvent,
+		Start:       event.TanggalPelaksanaan,
+		AllDay:      true,
+		Status:      "CONFIRMED",
+		Sequence:    int64(event.Revisi),
+		Stamp:       event.UpdatedAt,
+	}
+	if event.DeletedAt.Valid {
+		ical.Summary += " (Dibatalkan)"
+		ical.Status = "CANCELLED"
+		ical.Sequence++
+		ical.Stamp = event.DeletedAt.Time
+	}
+	return ical
+}
+
+func sendICalendar(c *fiber.Ctx, filename, body string) error {
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	if filename != "" {
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	}
+	return c.Status(http.StatusOK).SendString(body)
+}
+
+// getOrCreateKalenderFeed mengembalikan token feed user, membuat baru jika belum ada
+// atau jika regenerate diminta (token lama langsung tidak berlaku).
+func getOrCreateKalenderFeed(userID uint, regenerate bool) (models.KalenderFeed, error) {
+	db := database.GetGormDBInstance()
+	var feed models.KalenderFeed
+	err := db.Where("user_id = ?", userID).First(&feed).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return feed, err
+	}
+	if err == nil && !regenerate {
+		return feed, nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return feed, err
+	}
+	now := time.Now()
+	if feed.ID == 0 {
+		feed = models.KalenderFeed{UserID: userID, Token: token, CreatedAt: now, UpdatedAt: now}
+		return feed, db.Create(&feed).Error
+	}
+	feed.Token = token
+	feed.UpdatedAt = now
+	return feed, db.Save(&feed).Error
+}
+
+func kalenderFeedResponse(c *fiber.Ctx, regenerate bool) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+	feed, err := getOrCreateKalenderFeed(userID, regenerate)
+	if err != nil {
+		log.Printf("Failed to prepare calendar feed token: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to prepare calendar feed",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Calendar feed retrieved successfully",
+		Data: fiber.Map{
+			"token":    feed.Token,
+			"feed_url": c.BaseURL() + "/api/kalender/" + feed.Token + ".ics",
+		},
+	})
+}
+
+func GetKalenderFeed(c *fiber.Ctx) error {
+	return kalenderFeedResponse(c, false)
+}
+
+func RegenerateKalenderFeed(c *fiber.Ctx) error {
+	return kalenderFeedResponse(c, true)
+}
+
+// KalenderFeedICS adalah endpoint publik (tanpa login) yang diakses aplikasi kalender;
+// autentikasinya hanya lewat token rahasia pada URL.
+func KalenderFeedICS(c *fiber.Ctx) error {
+	token := strings.TrimSuffix(c.Params("token"), ".ics")
+	db := database.GetGormDBInstance()
+
+	var feed models.KalenderFeed
+	if err := db.Where("token = ?", token).First(&feed).Error; err != nil {
+		return c.Status(http.StatusNotFound).SendString("Calendar not found")
+	}
+	var user models.User
+	if err := db.Select("id", "role").First(&user, feed.UserID).Error; err != nil {
+		return c.Status(http.StatusNotFound).SendString("Calendar not found")
+	}
+
+	sejak := time.Now().AddDate(0, 0, -90)
+	query := db.Where("waktu_dimulai >= ?", sejak)
+	if user.Role == "admin" {
+		// Janji temu yang ditangani staf, termasuk yang dibatalkan agar ikut terhapus di kalender.
+		query = query.Where("(konselor_id = ? OR user_id_tolak_setujui = ?) AND status IN ?",
+			user.ID, user.ID, []string{"Disetujui", StatusJanjiTemuDiusulkanUlang, "Dibatalkan", "Ditolak"})
+	} else {
+		query = query.Where("user_id = ?", user.ID)
+	}
+	var janjiTemus []models.JanjiTemu
+	if err := query.Order("waktu_dimulai asc").Find(&janjiTemus).Error; err != nil {
+		log.Printf("Failed to load janji temu for calendar feed: %v", err)
+		return c.Status(http.StatusInternalServerError).SendString("Failed to build calendar")
+	}
+
+	// Unscoped: event yang dihapus tetap dikirim sebagai CANCELLED
+	var events []models.Event
+	if err := db.Unscoped().Where("tanggal_pelaksanaan >= ?", sejak).Order("tanggal_pelaksanaan asc").Find(&events).Error; err != nil {
+		log.Printf("Failed to load events for calendar feed: %v", err)
+		return c.Status(http.StatusInternalServerError).SendString("Failed to build calendar")
+	}
+
+	icalEvents := make([]helper.ICalEvent, 0, len(janjiTemus)+len(events))
+	for _, janjiTemu := range janjiTemus {
+		icalEvents = append(icalEvents, janjiTemuToICalEvent(janjiTemu))
+	}
+	for _, event := range events {
+		icalEvents = append(icalEvents, eventToICalEvent(event))
+	}
+
+	return sendICalendar(c, "", helper.BuildICalendar("PelitaPena", appTimezone(), icalEvents))
+}
+
+func DownloadJanjiTemuICS(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+
+	var janjiTemu models.JanjiTemu
+	if err := database.DB.First(&janjiTemu, c.Params("id")).Error; err != nil {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Janji temu not found",
+		})
+	}
+	if role != "admin" && janjiTemu.UserID != userID {
+		return c.Status(http.StatusForbidden).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "You are not authorized to access this janji temu",
+		})
+	}
+
+	body := helper.BuildICalendar("", appTimezone(), []helper.ICalEvent{janjiTemuToICalEvent(janjiTemu)})
+	return sendICalendar(c, fmt.Sprintf("janjitemu-%d.ics", janjiTemu.ID), body)
+}
+
+func DownloadEventICS(c *fiber.Ctx) error {
+	var event models.Event
+	if err := database.DB.First(&event, c.Params("id")).Error; err != nil {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Event not found",
+		})
+	}
+	body := helper.BuildICalendar("", appTimezone(), []helper.ICalEvent{eventToICalEvent(event)})
+	return sendICalendar(c, fmt.Sprintf("event-%d.ics", event.ID), body)
+}
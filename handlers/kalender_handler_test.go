@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestJanjiTemuToICalEventStatus(t *testing.T) {
+	kasus := map[string]string{
+		"Disetujui":                   "CONFIRMED",
+		"Belum disetujui":             "TENTATIVE",
+		StatusJanjiTemuDiusulkanUlang: "TENTATIVE",
+		"Dibatalkan":                  "CANCELLED",
+		"Ditolak":                     "CANCELLED",
+	}
+	for status, want := range kasus {
+		event := janjiTemuToICalEvent(models.JanjiTemu{ID: 5, Status: status, Revisi: 2})
+		if event.Status != want {
+			t.Errorf("status %q -> %s, want %s", status, event.Status, want)
+		}
+		if event.Sequence != 2 || event.UID != "janjitemu-5@pelitapena" {
+			t.Errorf("status %q: sequence=%d uid=%s", status, event.Sequence, event.UID)
+		}
+	}
+}
+
+func TestEventDihapusDikirimSebagaiCancelled(t *testing.T) {
+	dihapus := time.Date(2030, 1, 5, 12, 0, 0, 0, time.UTC)
+	event := models.Event{ID: 9, NamaEvent: "Sosialisasi", Revisi: 2, UpdatedAt: dihapus.Add(-time.Hour)}
+
+	if ical := eventToICalEvent(event); ical.Status != "CONFIRMED" || ical.Sequence != 2 {
+		t.Errorf("active event: status=%s sequence=%d, want CONFIRMED/2", ical.Status, ical.Sequence)
+	}
+
+	event.DeletedAt = gorm.DeletedAt{Time: dihapus, Valid: true}
+	ical := eventToICalEvent(event)
+	if ical.Status != "CANCELLED" {
+		t.Errorf("deleted event status = %s, want CANCELLED", ical.Status)
+	}
+	if ical.Sequence != 3 {
+		t.Errorf("deleted event sequence = %d, want 3 (higher than last published)", ical.Sequence)
+	}
+	if !ical.Stamp.Equal(dihapus) {
+		t.Errorf("deleted event stamp = %v, want deletion time", ical.Stamp)
+	}
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	KampanyeTerjadwal  = "Terjadwal"
+	KampanyeBerjalan   = "Berjalan"
+	KampanyeSelesai    = "Selesai"
+	KampanyeDibatalkan = "Dibatalkan"
+)
+
+const (
+	SegmenSemua             = "semua"
+	SegmenWilayah           = "wilayah"
+	SegmenKategoriKekerasan = "kategori_kekerasan"
+	SegmenEvent             = "event"
+)
+
+// kampanyeBatchSize membaca BROADCAST_BATCH_SIZE: jumlah penerima yang diantrekan
+// per kampanye setiap putaran worker (default 200), agar pengiriman tidak membanjiri
+// outbox dan FCM sekaligus.
+func kampanyeBatchSize() int {
+	if value, err := strconv.Atoi(os.Getenv("BROADCAST_BATCH_SIZE")); err == nil && value > 0 {
+		return value
+	}
+	return 200
+}
+
+// penerimaKampanye membangun query user masyarakat yang menjadi sasaran kampanye.
+func penerimaKampanye(db *gorm.DB, kampanye models.KampanyeNotifikasi) *gorm.DB {
+	query := db.Model(&models.User{}).Where("users.role = ?", "masyarakat")
+	switch kampanye.Segmen {
+	case SegmenWilayah:
+		query = query.Where("users.alamat LIKE ?", "%"+kampanye.NilaiSegmen+"%")
+	case SegmenKategoriKekerasan:
+		query = query.Where("EXISTS (SELECT 1 FROM laporans l WHERE l.user_id = users.id AND l.kategori_kekerasan_id = ?)", kampanye.NilaiSegmen)
+	case SegmenEvent:
+		query = query.Where("EXISTS (SELECT 1 FROM pendaftaran_events p WHERE p.user_id = users.id AND p.event_id = ?)", kampanye.NilaiSegmen)
+	}
+	return query
+}
+
+func validasiSegmen(segmen, nilai string) error {
+	switch segmen {
+	case SegmenSemua:
+		return nil
+	case SegmenWilayah:
+		if strings.TrimSpace(nilai) == "" {
+			return errors.New("Nama wilayah wajib diisi")
+		}
+		return nil
+	case SegmenKategoriKekerasan:
+		var kategori models.ViolenceCategory
+		if err := database.DB.First(&kategori, nilai).Error; err != nil {
+			return errors.New("Kategori kekerasan tidak ditemukan")
+		}
+		return nil
+	case SegmenEvent:
+		var event models.Event
+		if err := database.DB.First(&event, nilai).Error; err != nil {
+			return errors.New("Event tidak ditemukan")
+		}
+		return nil
+	}
+	return errors.New("Segmen harus salah satu dari: semua, wilayah, kategori_kekerasan, event")
+}
+
+// StartKampanyeNotifikasiWorker memproses kampanye yang sudah jatuh tempo secara
+// bertahap (BROADCAST_INTERVAL, default 30 detik).
+func StartKampanyeNotifikasiWorker() {
+	interval := 30 * time.Second
+	if value, err := time.ParseDuration(os.Getenv("BROADCAST_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			prosesKampanye(time.Now())
+			<-ticker.C
+		}
+	}()
+	log.Printf("Kampanye notifikasi worker started with interval %v", interval)
+}
+
+func prosesKampanye(now time.Time) {
+	var ids []uint
+	if err := database.GetGormDBInstance().Model(&models.KampanyeNotifikasi{}).
+		Where("status IN ? AND jadwal_kirim <= ?", []string{KampanyeTerjadwal, KampanyeBerjalan}, now).
+		Order("jadwal_kirim asc").
+		Pluck("id", &ids).Error; err != nil {
+		log.Printf("Failed to retrieve due kampanye: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if err := prosesSatuKampanye(id, now); err != nil {
+			log.Printf("Failed to process kampanye %d: %v", id, err)
+		}
+	}
+}
+
+// prosesSatuKampanye mengantrekan satu batch penerima. Baris kampanye dikunci
+// (SKIP LOCKED) supaya instance lain tidak memproses kampanye yang sama bersamaan.
+func prosesSatuKampanye(id uint, now time.Time) error {
+	diantrekan := false
+	var notifications []*models.Notification
+	err := database.GetGormDBInstance().Transaction(func(tx *gorm.DB) error {
+		var kampanye models.KampanyeNotifikasi
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND status IN ?", id, []string{KampanyeTerjadwal, KampanyeBerjalan}).
+			First(&kampanye).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		batchSize := kampanyeBatchSize()
+		var userIDs []uint
+		if err := penerimaKampanye(tx, kampanye).
+			Where("users.id > ?", kampanye.Kursor).
+			Order("users.id asc").
+			Limit(batchSize).
+			Pluck("users.id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		data := JenisPengumuman.Data(PayloadPengumuman{KampanyeID: kampanye.ID, Tautan: kampanye.DeepLink}, kampanye.DibuatOlehID, "", now)
+		for _, userID := range userIDs {
+			notification, err := NewNotificationFromFCMData(userID, kampanye.Judul, kampanye.Isi, data, now)
+			if err != nil {
+				return err
+			}
+			notification.KampanyeID = &kampanye.ID
+			if err := simpanDanAntrekan(tx, notification, data, now); err != nil {
+				return err
+			}
+			notifications = append(notifications, notification)
+			kampanye.Kursor = userID
+		}
+		diantrekan = len(userIDs) > 0
+
+		updates := map[string]interface{}{
+			"kursor":          kampanye.Kursor,
+			"jumlah_penerima": gorm.Expr("jumlah_penerima + ?", len(userIDs)),
+			"status":          KampanyeBerjalan,
+			"updated_at":      now,
+		}
+		if len(userIDs) < batchSize {
+			updates["status"] = KampanyeSelesai
+			updates["selesai_pada"] = now
+		}
+		return tx.Model(&kampanye).Updates(updates).Error
+	})
+	if err == nil && diantrekan {
+		notifikasiTersimpan(notifications...)
+	}
+	return err
+}
+
+/*=========================== ADMIN: KAMPANYE NOTIFIKASI =======================*/
+
+func AdminCreateKampanyeNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	now := time.Now()
+
+	kampanye := models.KampanyeNotifikasi{
+		Judul:        strings.TrimSpace(c.FormValue("judul")),
+		Isi:          strings.TrimSpace(c.FormValue("isi")),
+		DeepLink:     c.FormValue("deep_link"),
+		Segmen:       c.FormValue("segmen", SegmenSemua),
+		NilaiSegmen:  strings.TrimSpace(c.FormValue("nilai_segmen")),
+		JadwalKirim:  now,
+		Status:       KampanyeTerjadwal,
+		DibuatOlehID: userID,
+	}
+	if kampanye.Judul == "" || kampanye.Isi == "" {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Judul dan isi wajib diisi",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	if err := validasiSegmen(kampanye.Segmen, kampanye.NilaiSegmen); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: err.Error(),
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	if value := c.FormValue("jadwal_kirim"); value != "" {
+		jadwal, err := time.Parse("2006-01-02T15:04:05", value)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid jadwal_kirim format",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		kampanye.JadwalKirim = jadwal
+	}
+
+	if err := database.DB.Create(&kampanye).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal membuat kampanye notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusCreated,
+		Status:  "success",
+		Message: "Kampanye notifikasi berhasil dibuat",
+		Data:    kampanye,
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+func AdminGetKampanyeNotifikasi(c *fiber.Ctx) error {
+	query := database.DB.Order("jadwal_kirim desc")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var kampanye []models.KampanyeNotifikasi
+	if err := query.Find(&kampanye).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mengambil kampanye notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Daftar kampanye notifikasi",
+		Data:    kampanye,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// statistikKampanye: jumlah notifikasi per status pengiriman push dan yang sudah dibaca.
+type statistikKampanye struct {
+	Diantrekan        int64 `json:"diantrekan"`
+	Terkirim          int64 `json:"terkirim"`
+	Menunggu          int64 `json:"menunggu"`
+	Dilewati          int64 `json:"dilewati"`
+	Gagal             int64 `json:"gagal"`
+	Dibaca            int64 `json:"dibaca"`
+	PerkiraanPenerima int64 `json:"perkiraan_penerima"`
+}
+
+func hitungStatistikKampanye(db *gorm.DB, kampanye models.KampanyeNotifikasi) (statistikKampanye, error) {
+	var stat statistikKampanye
+	var rows []struct {
+		Status string
+		Jumlah int64
+	}
+	if err := db.Table("notification_outboxes o").
+		Select("o.status, COUNT(*) AS jumlah").
+		Joins("JOIN notifications n ON n.id = o.notification_id").
+		Where("n.kampanye_id = ? AND o.saluran = ?", kampanye.ID, SaluranPush).
+		Group("o.status").
+		Scan(&rows).Error; err != nil {
+		return stat, err
+	}
+	for _, row := range rows {
+		stat.Diantrekan += row.Jumlah
+		switch row.Status {
+		case OutboxStatusSent:
+			stat.Terkirim = row.Jumlah
+		case OutboxStatusPending:
+			stat.Menunggu = row.Jumlah
+		case OutboxStatusSkipped:
+			stat.Dilewati = row.Jumlah
+		case OutboxStatusDead:
+			stat.Gagal = row.Jumlah
+		}
+	}
+	if err := db.Model(&models.Notification{}).
+		Where("kampanye_id = ? AND is_read = ?", kampanye.ID, true).
+		Count(&stat.Dibaca).Error; err != nil {
+		return stat, err
+	}
+	if kampanye.Status == KampanyeSelesai || kampanye.Status == KampanyeDibatalkan {
+		stat.PerkiraanPenerima = int64(kampanye.JumlahPenerima)
+	} else if err := penerimaKampanye(db, kampanye).Count(&stat.PerkiraanPenerima).Error; err != nil {
+		return stat, err
+	}
+	return stat, nil
+}
+
+func AdminGetKampanyeNotifikasiByID(c *fiber.Ctx) error {
+	var kampanye models.KampanyeNotifikasi
+	if err := database.DB.First(&kampanye, c.Params("id")).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Kampanye notifikasi tidak ditemukan",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	stat, err := hitungStatistikKampanye(database.DB, kampanye)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menghitung statistik kampanye",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Detail kampanye notifikasi",
+		Data: fiber.Map{
+			"kampanye":  kampanye,
+			"statistik": stat,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// AdminBatalKampanyeNotifikasi menghentikan kampanye; notifikasi yang sudah
+// diantrekan tetap dikirim.
+func AdminBatalKampanyeNotifikasi(c *fiber.Ctx) error {
+	result := database.DB.Model(&models.KampanyeNotifikasi{}).
+		Where("id = ? AND status IN ?", c.Params("id"), []string{KampanyeTerjadwal, KampanyeBerjalan}).
+		Updates(map[string]interface{}{"status": KampanyeDibatalkan, "updated_at": time.Now()})
+	if result.Error != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal membatalkan kampanye notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if result.RowsAffected == 0 {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Kampanye tidak ditemukan atau sudah selesai",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	response := helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Kampanye notifikasi dibatalkan",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
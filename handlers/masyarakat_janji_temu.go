@@ -5,12 +5,15 @@ import (
 	"backend-pedika-fiber/database"
 	"backend-pedika-fiber/helper"
 	"backend-pedika-fiber/models"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
@@ -34,32 +37,98 @@ func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusBadRequest).JSON(response)
 	}
-	waktuDimulai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_dimulai"))
-	if err != nil {
-		response := helper.ResponseWithOutData{
-			Code:    http.StatusBadRequest,
-			Status:  "error",
-			Message: "Invalid format for start time",
-		}
-		return c.Status(http.StatusBadRequest).JSON(response)
-	}
-	waktuSelesai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_selesai"))
-	if err != nil {
-		response := helper.ResponseWithOutData{
-			Code:    http.StatusBadRequest,
-			Status:  "error",
-			Message: "Invalid format for end time",
-		}
-		return c.Status(http.StatusBadRequest).JSON(response)
-	}
-	janjitemu.WaktuDimulai = waktuDimulai
-	janjitemu.WaktuSelesai = waktuSelesai
+	janjitemu.ID = 0
 	janjitemu.Status = "Belum disetujui"
 	janjitemu.KeperluanKonsultasi = c.FormValue("keperluan_konsultasi")
 	janjitemu.UserID = uint(userID)
 	janjitemu.UserIDTolakSetujui = nil
+	janjitemu.NoRegistrasi = nil
+	if noRegistrasi := c.FormValue("no_registrasi"); noRegistrasi != "" {
+		if err := validasiLaporanMilikUser(noRegistrasi, uint(userID)); err != nil {
+			return responseErrorLaporanJanjiTemu(c, err)
+		}
+		janjitemu.NoRegistrasi = &noRegistrasi
+	}
+
+	// Jika masyarakat memilih slot jadwal konselor, waktu mengikuti slot tersebut.
+	var jadwalID uint64
+	if value := c.FormValue("jadwal_konselor_id"); value != "" {
+		jadwalID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid jadwal konselor ID",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+	} else {
+		waktuDimulai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_dimulai"))
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid format for start time",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		waktuSelesai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_selesai"))
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid format for end time",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		if err := validasiWaktuJanjiTemu(waktuDimulai, waktuSelesai); err != nil {
+			return responseErrorJadwal(c, err)
+		}
+		janjitemu.WaktuDimulai = waktuDimulai
+		janjitemu.WaktuSelesai = waktuSelesai
+		janjitemu.KonselorID = nil
+		janjitemu.JadwalKonselorID = nil
+
+		// Masyarakat boleh memilih konselor tertentu tanpa memesan slot. Jika tidak
+		// memilih, bentrok diperiksa saat admin menyetujui dan menjadi penanganannya
+		// (AdminApproveJanjiTemu).
+		if value := c.FormValue("konselor_id"); value != "" {
+			konselorID, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				response := helper.ResponseWithOutData{
+					Code:    http.StatusBadRequest,
+					Status:  "error",
+					Message: "Invalid konselor ID",
+				}
+				return c.Status(http.StatusBadRequest).JSON(response)
+			}
+			id := uint(konselorID)
+			janjitemu.KonselorID = &id
+		}
+	}
 
-	if err := database.DB.Create(&janjitemu).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if jadwalID != 0 {
+			if err := pesanSlotKonselor(tx, &janjitemu, uint(jadwalID)); err != nil {
+				return err
+			}
+		} else if janjitemu.KonselorID != nil {
+			if err := cekKonselorAktif(tx, *janjitemu.KonselorID); err != nil {
+				return err
+			}
+			if err := kunciKonselor(tx, *janjitemu.KonselorID); err != nil {
+				return err
+			}
+			if err := cekBentrokKonselor(tx, *janjitemu.KonselorID, janjitemu.WaktuDimulai, janjitemu.WaktuSelesai, 0); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&janjitemu).Error
+	})
+	if err != nil {
+		if errors.Is(err, errJadwalBentrok) || errors.Is(err, errSlotSudahDipesan) || errors.Is(err, errBukanKonselor) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return responseErrorJadwal(c, err)
+		}
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -68,6 +137,17 @@ func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 
+	if err := peringatkanAdmin(database.DB, peringatanAdmin{
+		Peristiwa: PeristiwaJanjiTemuBaru,
+		Kunci:     "admin_janji_temu_baru",
+		Vars:      varsJanjiTemu{WaktuDimulai: janjitemu.WaktuDimulai, WaktuSelesai: janjitemu.WaktuSelesai},
+		Referensi: formatID(janjitemu.ID),
+		Aktor:     janjitemu.UserID,
+		Penangan:  janjitemu.KonselorID,
+	}, time.Now()); err != nil {
+		log.Printf("Failed to alert admins about new janji temu: %v", err)
+	}
+
 	responseData := struct {
 		ID                  uint      `json:"id"`
 		UserID              uint      `json:"user_id"`
@@ -78,6 +158,9 @@ func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
 		UserTolakSetujui    uint      `json:"user_tolak_setujui"`
 		AlasanDitolak       string    `json:"alasan_ditolak"`
 		AlasanDibatalkan    string    `json:"alasan_dibatalkan"`
+		KonselorID          *uint     `json:"konselor_id,omitempty"`
+		JadwalKonselorID    *uint     `json:"jadwal_konselor_id,omitempty"`
+		NoRegistrasi        *string   `json:"no_registrasi,omitempty"`
 	}{
 		ID:                  janjitemu.ID,
 		UserID:              janjitemu.UserID,
@@ -88,6 +171,9 @@ func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
 		UserTolakSetujui:    0,
 		AlasanDitolak:       janjitemu.AlasanDitolak,
 		AlasanDibatalkan:    janjitemu.AlasanDibatalkan,
+		KonselorID:          janjitemu.KonselorID,
+		JadwalKonselorID:    janjitemu.JadwalKonselorID,
+		NoRegistrasi:        janjitemu.NoRegistrasi,
 	}
 
 	response := helper.ResponseWithData{
@@ -100,6 +186,15 @@ func MasyarakatCreateJanjiTemu(c *fiber.Ctx) error {
 }
 
 func MasyarakatEditJanjiTemu(c *fiber.Ctx) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		return c.Status(http.StatusUnauthorized).JSON(response)
+	}
 	janjiTemuID := c.Params("id")
 
 	var updateRequest struct {
@@ -128,6 +223,14 @@ func MasyarakatEditJanjiTemu(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusNotFound).JSON(response)
 	}
+	if janjiTemu.UserID != userID {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "Forbidden: You can only edit your own appointments",
+		}
+		return c.Status(http.StatusForbidden).JSON(response)
+	}
 	if janjiTemu.Status != "Belum disetujui" {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusForbidden,
@@ -136,11 +239,38 @@ func MasyarakatEditJanjiTemu(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusForbidden).JSON(response)
 	}
-	janjiTemu.WaktuDimulai = waktuDimulai
-	janjiTemu.WaktuSelesai = waktuSelesai
+	if err := validasiWaktuJanjiTemu(waktuDimulai, waktuSelesai); err != nil {
+		return responseErrorJadwal(c, err)
+	}
 	janjiTemu.KeperluanKonsultasi = c.FormValue("keperluan_konsultasi")
+	if noRegistrasi := c.FormValue("no_registrasi"); noRegistrasi != "" {
+		if err := validasiLaporanMilikUser(noRegistrasi, userID); err != nil {
+			return responseErrorLaporanJanjiTemu(c, err)
+		}
+		janjiTemu.NoRegistrasi = &noRegistrasi
+	}
 
-	if err := database.DB.Save(&janjiTemu).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		waktuBerubah := !janjiTemu.WaktuDimulai.Equal(waktuDimulai) || !janjiTemu.WaktuSelesai.Equal(waktuSelesai)
+		if waktuBerubah && janjiTemu.KonselorID != nil {
+			if err := kunciKonselor(tx, *janjiTemu.KonselorID); err != nil {
+				return err
+			}
+			if err := cekBentrokKonselor(tx, *janjiTemu.KonselorID, waktuDimulai, waktuSelesai, janjiTemu.ID); err != nil {
+				return err
+			}
+			// Waktu tidak lagi mengikuti slot yang dipesan sebelumnya.
+			janjiTemu.JadwalKonselorID = nil
+		}
+		janjiTemu.WaktuDimulai = waktuDimulai
+		janjiTemu.WaktuSelesai = waktuSelesai
+		janjiTemu.Revisi++
+		return tx.Save(&janjiTemu).Error
+	})
+	if err != nil {
+		if errors.Is(err, errJadwalBentrok) {
+			return responseErrorJadwal(c, err)
+		}
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -188,15 +318,23 @@ func GetUserJanjiTemus(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "List of JanjiTemu by user",
-		Data:    janjiTemus,
+		Data:    janjiTemuMilikSendiri(janjiTemus...),
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
 
 func GetJanjiTemuByID(c *fiber.Ctx) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
 	janjiTemuID := c.Params("id")
 	var janjiTemu models.JanjiTemu
-	if err := database.DB.Preload("UserTolakSetujui").First(&janjiTemu, janjiTemuID).Error; err != nil {
+	if err := database.DB.Preload("UserTolakSetujui").Preload("UsulanJadwal").First(&janjiTemu, janjiTemuID).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusNotFound,
 			Status:  "error",
@@ -204,6 +342,13 @@ func GetJanjiTemuByID(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusNotFound).JSON(response)
 	}
+	if janjiTemu.UserID != userID {
+		return c.Status(http.StatusForbidden).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "You are not allowed to view this JanjiTemu",
+		})
+	}
 	if janjiTemu.Status == "Ditolak" && janjiTemu.UserTolakSetujui.ID != 0 {
 		var user models.User
 		if err := database.DB.First(&user, janjiTemu.UserIDTolakSetujui).Error; err != nil {
@@ -220,7 +365,7 @@ func GetJanjiTemuByID(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "JanjiTemu detail",
-		Data:    janjiTemu,
+		Data:    janjiTemuMilikSendiri(janjiTemu)[0],
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -247,6 +392,7 @@ func MasyarakatCancelJanjiTemu(c *fiber.Ctx) error {
 	}
 	janjiTemu.Status = "Dibatalkan"
 	janjiTemu.AlasanDibatalkan = c.FormValue("alasan_dibatalkan")
+	janjiTemu.Revisi++
 
 	if err := database.DB.Save(&janjiTemu).Error; err != nil {
 		response := helper.ResponseWithOutData{
@@ -266,8 +412,12 @@ func MasyarakatCancelJanjiTemu(c *fiber.Ctx) error {
 }
 
 func AdminGetAllJanjiTemu(c *fiber.Ctx) error {
+	query := database.DB.Preload("User").Preload("UserTolakSetujui")
+	if noRegistrasi := c.Query("no_registrasi"); noRegistrasi != "" {
+		query = query.Where("no_registrasi = ?", noRegistrasi)
+	}
 	var janjiTemus []models.JanjiTemu
-	if err := database.DB.Preload("User").Preload("UserTolakSetujui").Find(&janjiTemus).Error; err != nil {
+	if err := query.Find(&janjiTemus).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -280,7 +430,7 @@ func AdminGetAllJanjiTemu(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "List of Janji Temu",
-		Data:    janjiTemus,
+		Data:    sanitizedJanjiTemu(c, "", janjiTemus),
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -288,7 +438,7 @@ func AdminGetAllJanjiTemu(c *fiber.Ctx) error {
 func AdminJanjiTemuByID(c *fiber.Ctx) error {
 	janjiTemuID := c.Params("id")
 	var janjiTemu models.JanjiTemu
-	if err := database.DB.Preload("UserTolakSetujui").Preload("User").First(&janjiTemu, janjiTemuID).Error; err != nil {
+	if err := database.DB.Preload("UserTolakSetujui").Preload("User").Preload("UsulanJadwal").First(&janjiTemu, janjiTemuID).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusNotFound,
 			Status:  "error",
@@ -300,7 +450,7 @@ func AdminJanjiTemuByID(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "JanjiTemu detail",
-		Data:    janjiTemu,
+		Data:    sanitizedJanjiTemu(c, janjiTemuID, janjiTemu),
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -360,55 +510,56 @@ func AdminApproveJanjiTemu(c *fiber.Ctx) error {
     janjiTemu.UserIDTolakSetujui = &userID // ID admin yang menyetujui
     janjiTemu.Status = "Disetujui"
     now := time.Now()
-    if err := db.Save(&janjiTemu).Error; err != nil {
-        return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
-            Code:    http.StatusInternalServerError,
-            Status:  "error",
-            Message: "Gagal menyimpan perubahan status",
-        })
-    }
 
-    // Cari pengguna untuk notifikasi
-    var user models.User
-    if err := db.Where("id = ?", janjiTemu.UserID).First(&user).Error; err != nil {
-        log.Printf("Failed to retrieve user for notification: %v", err)
-    } else if user.NotificationToken != "" {
-        log.Println("User notification token:", user.NotificationToken)
-
-        // Siapkan data notifikasi FCM
-        notificationData := models.FCMNotificationData{
-            Type:      "appointment",
-            ReportID:  id, // Gunakan ID janji temu sebagai identifier
-            Status:    "approved",
-            UpdatedBy: userID, // ID admin yang menyetujui
-            UpdatedAt: now.Format(time.RFC3339),
-            Notes:     "Kami sudah siap bertemu dengan Anda!",
-            DeepLink:  "laporanku://appointments/" + id, // Deep link opsional
+    notificationData := JenisJanjiTemu.Data(
+        PayloadJanjiTemu{JanjiTemuID: janjiTemu.ID, Status: "approved"},
+        userID, // ID admin yang menyetujui
+        "Kami sudah siap bertemu dengan Anda!",
+        now,
+    )
+    notificationData.Urgent = true // keputusan jadwal; dialihkan ke SMS jika tidak ada perangkat
+
+    // Janji temu tanpa slot ditangani oleh admin yang menyetujui; pastikan tidak bentrok.
+    if janjiTemu.KonselorID == nil {
+        janjiTemu.KonselorID = &userID
+    }
+    var notifikasi *models.Notification
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := kunciKonselor(tx, *janjiTemu.KonselorID); err != nil {
+            return err
         }
-
-        // Buat notifikasi untuk disimpan di database dengan pesan interaktif
-		
-        notification, err := NewNotificationFromFCMData(
+        if err := cekBentrokKonselor(tx, *janjiTemu.KonselorID, janjiTemu.WaktuDimulai, janjiTemu.WaktuSelesai, janjiTemu.ID); err != nil {
+            return err
+        }
+        // Menyetujui waktu yang sekarang berarti usulan jadwal lain tidak berlaku lagi.
+        if err := tutupUsulanMenunggu(tx, janjiTemu.ID, now); err != nil {
+            return err
+        }
+        janjiTemu.Revisi++
+        if err := tx.Save(&janjiTemu).Error; err != nil {
+            return err
+        }
+        var err error
+        notifikasi, err = antrekanNotifikasiTemplate(tx,
             janjiTemu.UserID,
-            "Yay! Janji Pertemuan Kamu Telah Disetujui!",
-            "Hore! Jadwal janji temu kamu pada "+janjiTemu.WaktuDimulai.Format(time.RFC3339)+" telah disetujui. Jangan lupakan janji kita ya!",
+            "janji_temu_disetujui",
+            varsJanjiTemu{WaktuDimulai: janjiTemu.WaktuDimulai, WaktuSelesai: janjiTemu.WaktuSelesai},
             notificationData,
             now,
         )
-        if err != nil {
-            log.Printf("Error creating notification: %v", err)
-        } else {
-            
-            if err := db.Create(&notification).Error; err != nil {
-                log.Printf("Failed to store notification: %v", err)
-            }
-
-            
-            if err := SendFCMNotification(user.NotificationToken, notificationData,*notification); err != nil {
-                log.Printf("Failed to send FCM notification: %v", err)
-            }
+        return err
+    })
+    if err != nil {
+        if errors.Is(err, errJadwalBentrok) {
+            return responseErrorJadwal(c, err)
         }
+        return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+            Code:    http.StatusInternalServerError,
+            Status:  "error",
+            Message: "Gagal menyimpan perubahan status",
+        })
     }
+    notifikasiTersimpan(notifikasi)
 
     // Response sukses
     response := helper.ResponseWithOutData{
@@ -485,56 +636,45 @@ func AdminCancelJanjiTemu(c *fiber.Ctx) error {
     // Update status janji temu
     janjiTemu.Status = "Ditolak"
     janjiTemu.UserIDTolakSetujui = &userID
-    janjiTemu.AlasanDitolak = alasanDitolak 
+    janjiTemu.AlasanDitolak = alasanDitolak
     now := time.Now()
-    if err := db.Save(&janjiTemu).Error; err != nil {
-        response := helper.ResponseWithOutData{
-            Code:    http.StatusInternalServerError,
-            Status:  "error",
-            Message: "Failed to cancel janji temu",
-        }
-        return c.Status(http.StatusInternalServerError).JSON(response)
-    }
 
-    // Cari pengguna untuk notifikasi
-    var user models.User
-    if err := db.Where("id = ?", janjiTemu.UserID).First(&user).Error; err != nil {
-        log.Printf("Failed to retrieve user for notification: %v", err)
-    } else if user.NotificationToken != "" {
-        log.Println("User notification token:", user.NotificationToken)
-
-        notificationData := models.FCMNotificationData{
-            Type:      "appointment",
-            ReportID:  janjiTemuID, 
-            Status:    "rejected",
-            UpdatedBy: userID, 
-            UpdatedAt: now.Format(time.RFC3339),
-            Notes:     "Maaf, janji temu Anda ditolak karena: " + alasanDitolak,
-            DeepLink:  "laporanku://appointments/" + janjiTemuID,
+    notificationData := JenisJanjiTemu.Data(
+        PayloadJanjiTemu{JanjiTemuID: janjiTemu.ID, Status: "rejected"},
+        userID,
+        "Maaf, janji temu Anda ditolak karena: "+alasanDitolak,
+        now,
+    )
+    notificationData.Urgent = true
+
+    var notifikasi *models.Notification
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tutupUsulanMenunggu(tx, janjiTemu.ID, now); err != nil {
+            return err
         }
-
-        
-        notification, err := NewNotificationFromFCMData(
+        janjiTemu.Revisi++
+        if err := tx.Save(&janjiTemu).Error; err != nil {
+            return err
+        }
+        var err error
+        notifikasi, err = antrekanNotifikasiTemplate(tx,
             janjiTemu.UserID,
-            "Oops! Janji Pertemuan Kamu Ditolak..",
-            "Sayang sekali, janji temu kamu pada "+janjiTemu.WaktuDimulai.Format(time.RFC3339)+" ditolak. Alasan: "+janjiTemu.AlasanDitolak,
+            "janji_temu_ditolak",
+            varsJanjiTemu{WaktuDimulai: janjiTemu.WaktuDimulai, WaktuSelesai: janjiTemu.WaktuSelesai, Alasan: janjiTemu.AlasanDitolak},
             notificationData,
             now,
         )
-        if err != nil {
-            log.Printf("Error creating notification: %v", err)
-        } else {
-            // Simpan notifikasi ke database
-            if err := db.Create(notification).Error; err != nil {
-                log.Printf("Failed to store notification: %v", err)
-            }
-
-            // Kirim push notification via FCM
-            if err := SendFCMNotification(user.NotificationToken, notificationData, *notification); err != nil {
-                log.Printf("Failed to send FCM notification: %v", err)
-            }
+        return err
+    })
+    if err != nil {
+        response := helper.ResponseWithOutData{
+            Code:    http.StatusInternalServerError,
+            Status:  "error",
+            Message: "Failed to cancel janji temu",
         }
+        return c.Status(http.StatusInternalServerError).JSON(response)
     }
+    notifikasiTersimpan(notifikasi)
 
     // Response sukses
     response := helper.ResponseWithOutData{
@@ -543,4 +683,64 @@ func AdminCancelJanjiTemu(c *fiber.Ctx) error {
         Message: "Janji Temu Sudah Ditolak",
     }
     return c.Status(http.StatusOK).JSON(response)
-}
\ No newline at end of file
+}
+
+// sanitizedJanjiTemu menyamarkan data user (pemohon & admin) yang ikut ter-preload.
+func sanitizedJanjiTemu(c *fiber.Ctx, janjiTemuID string, data any) any {
+	sanitized, err := sanitizeResponseData(c, "janji_temu", janjiTemuID, data)
+	if err != nil {
+		log.Printf("Failed to sanitize janji temu data: %v", err)
+		return nil
+	}
+	return sanitized
+}
+
+// janjiTemuMilikSendiri dipakai untuk janji temu milik pemanggil: data pemohon tidak
+// disamarkan, tetapi data petugas yang menyetujui/menolak tetap disamarkan.
+func janjiTemuMilikSendiri(janjiTemus ...models.JanjiTemu) []any {
+	hasil := make([]any, 0, len(janjiTemus))
+	for _, janjiTemu := range janjiTemus {
+		data, ok := ownResponseData(janjiTemu).(map[string]any)
+		if !ok {
+			hasil = append(hasil, nil)
+			continue
+		}
+		petugas, _, err := helper.SanitizeForRole(janjiTemu.UserTolakSetujui, "", false)
+		if err != nil {
+			log.Printf("Failed to sanitize janji temu staff data: %v", err)
+			petugas = nil
+		}
+		data["user_tolak_setujui"] = petugas
+		hasil = append(hasil, data)
+	}
+	return hasil
+}
+
+var errLaporanBukanMilikUser = errors.New("laporan bukan milik pemohon janji temu")
+
+// validasiLaporanMilikUser memastikan laporan yang dirujuk ada dan dibuat oleh user tersebut.
+func validasiLaporanMilikUser(noRegistrasi string, userID uint) error {
+	var laporan models.Laporan
+	if err := database.DB.Select("no_registrasi", "user_id").Where("no_registrasi = ?", noRegistrasi).First(&laporan).Error; err != nil {
+		return err
+	}
+	if laporan.UserID != userID {
+		return errLaporanBukanMilikUser
+	}
+	return nil
+}
+
+func responseErrorLaporanJanjiTemu(c *fiber.Ctx, err error) error {
+	status := http.StatusInternalServerError
+	message := "Failed to validate laporan"
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errLaporanBukanMilikUser) {
+		// Laporan milik orang lain dilaporkan sebagai tidak ditemukan agar tidak bocor.
+		status = http.StatusBadRequest
+		message = "Laporan tidak ditemukan"
+	}
+	return c.Status(status).JSON(helper.ResponseWithOutData{
+		Code:    status,
+		Status:  "error",
+		Message: message,
+	})
+}
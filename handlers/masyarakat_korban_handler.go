@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 func CreateKorban(c *fiber.Ctx) error {
@@ -40,34 +41,20 @@ func CreateKorban(c *fiber.Ctx) error {
 	korban.HubunganDenganKorban = c.FormValue("hubungan_dengan_pelaku")
 	korban.KeteranganLainnya = c.FormValue("keterangan_lainnya")
 
-	file, err := c.FormFile("dokumentasi_korban")
-	if err == nil {
-		src, err := file.Open()
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to open image file",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
-		}
-		defer src.Close()
-
-		imageURL, err := helper.UploadFileToCloudinary(src, file.Filename)
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Gagal Mengupload gambar",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
+	dokumentasi, err := uploadDokumentasiFiles(c, "dokumentasi_korban", "caption_dokumentasi")
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal Mengupload gambar",
 		}
-
-		korban.DokumentasiPelaku = imageURL
+		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	korban.Dokumentasi = dokumentasi
 	korban.CreatedAt = time.Now()
 	korban.UpdatedAt = time.Now()
 	if err := database.DB.Create(&korban).Error; err != nil {
+		hapusUploadDokumentasi(dokumentasi)
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -151,34 +138,25 @@ func UpdateKorban(c *fiber.Ctx) error {
 		korban.KeteranganLainnya = value
 	}
 
-	file, err := c.FormFile("dokumentasi_korban")
-	if err == nil {
-		src, err := file.Open()
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to open image file",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
-		}
-		defer src.Close()
-
-		imageURL, err := helper.UploadFileToCloudinary(src, file.Filename)
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to upload image",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
+	dokumentasiBaru, err := uploadDokumentasiFiles(c, "dokumentasi_korban", "caption_dokumentasi")
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to upload image",
 		}
-
-		korban.DokumentasiPelaku = imageURL
+		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 
 	korban.UpdatedAt = time.Now()
-	if err := database.DB.Save(&korban).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Dokumentasi").Save(&korban).Error; err != nil {
+			return err
+		}
+		return simpanPerubahanDokumentasi(tx, "korban", korban.ID, dokumentasiIDsToDelete(c), dokumentasiBaru)
+	})
+	if err != nil {
+		hapusUploadDokumentasi(dokumentasiBaru)
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -186,6 +164,7 @@ func UpdateKorban(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	database.DB.Preload("Dokumentasi").First(&korban, korban.ID)
 
 	response := helper.ResponseWithData{
 		Code:    http.StatusOK,
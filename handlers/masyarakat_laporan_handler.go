@@ -7,6 +7,7 @@ import (
 	"backend-pedika-fiber/models"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"sync"
@@ -126,6 +127,17 @@ func CreateLaporan(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	terbitkanLaporanAdmin(EventLaporanBaru, laporan)
+	if err := peringatkanAdmin(database.GetGormDBInstance(), peringatanAdmin{
+		Peristiwa:  PeristiwaLaporanBaru,
+		Kunci:      "admin_laporan_baru",
+		Vars:       varsLaporan{NoRegistrasi: laporan.NoRegistrasi},
+		Referensi:  laporan.NoRegistrasi,
+		Aktor:      laporan.UserID,
+		KategoriID: laporan.KategoriKekerasanID,
+	}, laporan.CreatedAt); err != nil {
+		log.Printf("Failed to alert admins about new laporan: %v", err)
+	}
 
 	response := helper.ResponseWithData{
 		Code:    http.StatusCreated,
@@ -423,7 +435,7 @@ func GetReportByNoRegistrasi(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 	var pelaku []models.Pelaku
-	if err := db.Where("no_registrasi = ?", noRegistrasi).Find(&pelaku).Error; err != nil {
+	if err := db.Preload("Dokumentasi").Where("no_registrasi = ?", noRegistrasi).Find(&pelaku).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -432,7 +444,7 @@ func GetReportByNoRegistrasi(c *fiber.Ctx) error {
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 	var korban []models.Korban
-	if err := db.Where("no_registrasi = ?", noRegistrasi).Find(&korban).Error; err != nil {
+	if err := db.Preload("Dokumentasi").Where("no_registrasi = ?", noRegistrasi).Find(&korban).Error; err != nil {
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -440,6 +452,15 @@ func GetReportByNoRegistrasi(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	var konsultasi []models.JanjiTemu
+	if err := db.Where("no_registrasi = ?", noRegistrasi).Order("waktu_dimulai asc").Find(&konsultasi).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to fetch konsultasi details",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
 	var userMelihat models.User
 	if laporan.UserIDMelihat != nil {
 		if err := db.First(&userMelihat, *laporan.UserIDMelihat).Error; err != nil {
@@ -456,12 +477,14 @@ func GetReportByNoRegistrasi(c *fiber.Ctx) error {
 		TrackingLaporan []models.TrackingLaporan `json:"tracking_laporan"`
 		Pelaku          []models.Pelaku          `json:"pelaku"`
 		Korban          []models.Korban          `json:"korban"`
+		Konsultasi      []models.JanjiTemu       `json:"konsultasi"`
 		UserMelihat     *models.User             `json:"user_melihat,omitempty"`
 	}{
 		Laporan:         laporan,
 		TrackingLaporan: trackingLaporan,
 		Pelaku:          pelaku,
 		Korban:          korban,
+		Konsultasi:      konsultasi,
 		UserMelihat:     nil,
 	}
 	if laporan.UserIDMelihat != nil {
@@ -472,7 +495,7 @@ func GetReportByNoRegistrasi(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "Report detail retrieved successfully",
-		Data:    responseData,
+		Data:    ownResponseData(responseData),
 	}
 
 	return c.Status(http.StatusOK).JSON(response)
@@ -524,6 +547,17 @@ func BatalkanLaporan(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	terbitkanLaporanAdmin(EventStatusLaporan, laporan)
+	if err := peringatkanAdmin(db, peringatanAdmin{
+		Peristiwa:  PeristiwaLaporanDibatalkan,
+		Kunci:      "admin_laporan_dibatalkan",
+		Vars:       varsLaporanDibatalkan{NoRegistrasi: laporan.NoRegistrasi, Alasan: laporan.AlasanDibatalkan},
+		Referensi:  laporan.NoRegistrasi,
+		Aktor:      laporan.UserID,
+		KategoriID: laporan.KategoriKekerasanID,
+	}, now); err != nil {
+		log.Printf("Failed to alert admins about cancelled laporan: %v", err)
+	}
 
 	response := helper.ResponseWithData{
 		Code:    http.StatusOK,
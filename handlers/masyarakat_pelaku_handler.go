@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 func CreatePelaku(c *fiber.Ctx) error {
@@ -40,34 +41,20 @@ func CreatePelaku(c *fiber.Ctx) error {
 	pelaku.HubunganDenganKorban = c.FormValue("hubungan_dengan_korban")
 	pelaku.KeteranganLainnya = c.FormValue("keterangan_lainnya")
 
-	file, err := c.FormFile("dokumentasi_pelaku")
-	if err == nil {
-		src, err := file.Open()
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to open image file",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
-		}
-		defer src.Close()
-
-		imageURL, err := helper.UploadFileToCloudinary(src, file.Filename)
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Gagal Mengupload Gambar",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
+	dokumentasi, err := uploadDokumentasiFiles(c, "dokumentasi_pelaku", "caption_dokumentasi")
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal Mengupload Gambar",
 		}
-
-		pelaku.DokumentasiPelaku = imageURL
+		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	pelaku.Dokumentasi = dokumentasi
 	pelaku.CreatedAt = time.Now()
 	pelaku.UpdatedAt = time.Now()
 	if err := database.DB.Create(&pelaku).Error; err != nil {
+		hapusUploadDokumentasi(dokumentasi)
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -151,34 +138,25 @@ func UpdatePelaku(c *fiber.Ctx) error {
 		pelaku.KeteranganLainnya = value
 	}
 
-	file, err := c.FormFile("dokumentasi_pelaku")
-	if err == nil {
-		src, err := file.Open()
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Failed to open image file",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
-		}
-		defer src.Close()
-
-		imageURL, err := helper.UploadFileToCloudinary(src, file.Filename)
-		if err != nil {
-			response := helper.ResponseWithOutData{
-				Code:    http.StatusInternalServerError,
-				Status:  "error",
-				Message: "Gagal Mengupload Gambat",
-			}
-			return c.Status(http.StatusInternalServerError).JSON(response)
+	dokumentasiBaru, err := uploadDokumentasiFiles(c, "dokumentasi_pelaku", "caption_dokumentasi")
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to upload image",
 		}
-
-		pelaku.DokumentasiPelaku = imageURL
+		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
 
 	pelaku.UpdatedAt = time.Now()
-	if err := database.DB.Save(&pelaku).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Dokumentasi").Save(&pelaku).Error; err != nil {
+			return err
+		}
+		return simpanPerubahanDokumentasi(tx, "pelaku", pelaku.ID, dokumentasiIDsToDelete(c), dokumentasiBaru)
+	})
+	if err != nil {
+		hapusUploadDokumentasi(dokumentasiBaru)
 		response := helper.ResponseWithOutData{
 			Code:    http.StatusInternalServerError,
 			Status:  "error",
@@ -186,6 +164,7 @@ func UpdatePelaku(c *fiber.Ctx) error {
 		}
 		return c.Status(http.StatusInternalServerError).JSON(response)
 	}
+	database.DB.Preload("Dokumentasi").First(&pelaku, pelaku.ID)
 
 	response := helper.ResponseWithData{
 		Code:    http.StatusOK,
@@ -9,15 +9,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
-	firebase "firebase.google.com/go"
 	"firebase.google.com/go/messaging"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
-	"google.golang.org/api/option"
+	"gorm.io/gorm"
 )
 
 func NewNotificationFromFCMData(userID uint, title, body string, data models.FCMNotificationData, now time.Time) (*models.Notification, error) {
@@ -38,52 +36,77 @@ func NewNotificationFromFCMData(userID uint, title, body string, data models.FCM
 	}, nil
 }
 
-func SendFCMNotification(token string, data models.FCMNotificationData, notification models.Notification) error {
-    ctx := context.Background()
-
- 
-    opt := option.WithCredentialsFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
-    app, err := firebase.NewApp(ctx, nil, opt)
-    if err != nil {
-        log.Printf("Error initializing Firebase app: %v", err)
-        return err
-    }
-
+// buildFCMMessage menyusun pesan FCM tanpa token tujuan.
+func buildFCMMessage(data models.FCMNotificationData, notification models.Notification) *messaging.Message {
+	message := &messaging.Message{
+		Data: map[string]string{
+			"type":      data.Type,
+			"reportId":  data.ReportID,
+			"status":    data.Status,
+			"updatedBy": fmt.Sprintf("%d", data.UpdatedBy),
+			"updatedAt": data.UpdatedAt,
+			"notes":     data.Notes,
+			"deepLink":  data.DeepLink,
+			"imageUrl":  data.ImageURL,
+		},
+		Notification: &messaging.Notification{
+			Title: notification.Title,
+			Body:  notification.Body,
+		},
+	}
+	if data.Version > 0 {
+		message.Data["kind"] = data.Kind
+		message.Data["version"] = strconv.Itoa(data.Version)
+		message.Data["payload"] = data.Payload
+	}
+	return message
+}
 
-    client, err := app.Messaging(ctx)
-    if err != nil {
-        log.Printf("Error getting Messaging client: %v", err)
-        return err
-    }
+func SendFCMNotification(token string, data models.FCMNotificationData, notification models.Notification) error {
+	client, err := helper.GetPushProvider()
+	if err != nil {
+		log.Printf("Error getting push provider: %v", err)
+		return err
+	}
 
+	message := buildFCMMessage(data, notification)
+	message.Token = token
 
-    message := &messaging.Message{
-        Token: token,
-        Data: map[string]string{
-            "type":      data.Type,
-            "reportId":  data.ReportID,
-            "status":    data.Status,
-            "updatedBy": fmt.Sprintf("%d", data.UpdatedBy),
-            "updatedAt": data.UpdatedAt,
-            "notes":     data.Notes,
-            "deepLink":  data.DeepLink,
-            "imageUrl":  data.ImageURL,
-        },
-        Notification: &messaging.Notification{
-            Title:notification.Title,
-            Body:  notification.Body,
-        },
-    }
+	response, err := client.Send(context.Background(), message)
+	if err != nil {
+		log.Printf("Error sending FCM message: %v", err)
+		return err
+	}
 
+	log.Printf("Successfully sent FCM message: %s", response)
+	return nil
+}
 
-    response, err := client.Send(ctx, message)
-    if err != nil {
-        log.Printf("Error sending FCM message: %v", err)
-        return err
-    }
+// SendFCMNotificationMulticast mengirim notifikasi yang sama ke banyak token dan
+// mengembalikan hasil per token.
+func SendFCMNotificationMulticast(tokens []string, data models.FCMNotificationData, notification models.Notification) ([]helper.FCMResult, error) {
+	client, err := helper.GetPushProvider()
+	if err != nil {
+		return nil, err
+	}
+	messages := helper.MulticastMessages(tokens, buildFCMMessage(data, notification))
+	return client.SendBatch(context.Background(), messages), nil
+}
 
-    log.Printf("Successfully sent FCM message: %s", response)
-    return nil
+// kirimNotifikasi menyimpan notifikasi untuk user dan mengantrekan push-nya di
+// outbox; pengiriman dilakukan oleh worker outbox.
+func kirimNotifikasi(db *gorm.DB, userID uint, title, body string, data models.FCMNotificationData, now time.Time) error {
+	var notification *models.Notification
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		notification, err = antrekanNotifikasi(tx, userID, title, body, data, now)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	notifikasiTersimpan(notification)
+	return nil
 }
 
 func StoreNotification(userID uint, notificationData models.FCMNotificationData) error {
@@ -149,22 +172,14 @@ func GetUserNotifications(c *fiber.Ctx) error {
     // Ambil parameter query untuk pagination dan filter
     page, _ := strconv.Atoi(c.Query("page", "1"))
     limit, _ := strconv.Atoi(c.Query("limit", "10"))
-    isRead := c.Query("is_read", "") 
 
     offset := (page - 1) * limit
 
     // Query database
     db := database.GetGormDBInstance()
     var notifications []models.Notification
-    query := db.Where("user_id = ?", userID)
-
-    // Tambahkan filter is_read jika ada
-    if isRead != "" {
-        readBool, err := strconv.ParseBool(isRead)
-        if err == nil {
-            query = query.Where("is_read = ?", readBool)
-        }
-    }
+    // Filter type, is_read dan archived (notifikasi arsip tidak tampil secara default)
+    query := filterInbox(db.Where("user_id = ?", userID), c)
 
     // Hitung total notifikasi untuk pagination
     var total int64
@@ -369,6 +384,7 @@ func MarkNotificationAsRead(c *fiber.Ctx) error {
             Message: "Failed to mark notification as read",
         })
     }
+    terbitkanJumlahBelumDibaca(db, userID)
 
     // Response sukses
     response := helper.ResponseWithOutData{
@@ -413,7 +429,7 @@ func GetUnreadNotificationsCount(c *fiber.Ctx) error {
     db := database.GetGormDBInstance()
     var count int64
     if err := db.Model(&models.Notification{}).
-        Where("user_id = ? AND is_read = ?", userID, false).
+        Where("user_id = ? AND is_read = ? AND archived_at IS NULL", userID, false).
         Count(&count).Error; err != nil {
         log.Printf("Failed to count unread notifications: %v", err)
         return c.Status(fiber.StatusInternalServerError).JSON(helper.ResponseWithOutData{
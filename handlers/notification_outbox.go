@@ -0,0 +1,442 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"firebase.google.com/go/messaging"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusSkipped = "skipped"
+	OutboxStatusDead    = "dead"
+)
+
+const (
+	outboxBatchSize = 50
+	// outboxBatchEmail lebih kecil karena setiap email bisa memakan sampai batas
+	// waktu SMTP; satu batch harus selesai sebelum outboxLease habis.
+	outboxBatchEmail  = 10
+	outboxBackoffAwal = 30 * time.Second
+	outboxBackoffMaks = time.Hour
+	// outboxLease: baris yang sedang dikirim ditunda selama ini agar tidak diambil
+	// worker lain; jika proses mati di tengah jalan, baris otomatis dicoba lagi.
+	outboxLease = 5 * time.Minute
+)
+
+var errTanpaNotificationToken = errors.New("user has no active device")
+
+// antrianOutbox adalah satu loop worker beserta saluran yang ditanganinya. Email
+// punya loop sendiri agar server SMTP yang lambat tidak menunda push dan SMS.
+type antrianOutbox struct {
+	nama      string
+	saluran   []string
+	batchSize int
+	// wake membangunkan loop lebih awal setelah ada notifikasi baru.
+	wake chan struct{}
+}
+
+var (
+	antrianPush  = &antrianOutbox{nama: "push", saluran: []string{SaluranPush, SaluranSMS, SaluranWhatsApp}, batchSize: outboxBatchSize, wake: make(chan struct{}, 1)}
+	antrianEmail = &antrianOutbox{nama: "email", saluran: []string{SaluranEmail}, batchSize: outboxBatchEmail, wake: make(chan struct{}, 1)}
+)
+
+func bangunkanOutbox() {
+	for _, antrian := range []*antrianOutbox{antrianPush, antrianEmail} {
+		select {
+		case antrian.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func outboxMaxAttempts() int {
+	if value, err := strconv.Atoi(os.Getenv("NOTIFICATION_OUTBOX_MAX_ATTEMPTS")); err == nil && value > 0 {
+		return value
+	}
+	return 8
+}
+
+// outboxBackoff: 30 detik, 1 menit, 2 menit, ... maksimal 1 jam.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBackoffAwal
+	for i := 1; i < attempts && delay < outboxBackoffMaks; i++ {
+		delay *= 2
+	}
+	if delay > outboxBackoffMaks {
+		delay = outboxBackoffMaks
+	}
+	return delay
+}
+
+// antrekanNotifikasi menyimpan notifikasi (inbox) dan baris outbox untuk push.
+// Panggil dengan tx milik perubahan data agar keduanya commit atau rollback bersama.
+// Preferensi user menentukan apakah push dilewati atau ditunda (jam tenang) dan
+// apakah salinan email ikut diantrekan.
+func antrekanNotifikasi(tx *gorm.DB, userID uint, title, body string, data models.FCMNotificationData, now time.Time) (*models.Notification, error) {
+	notification, err := NewNotificationFromFCMData(userID, title, body, data, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := simpanDanAntrekan(tx, notification, data, now); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// simpanDanAntrekan dipakai jika pemanggil perlu mengisi field tambahan pada
+// notification sebelum disimpan (misalnya KampanyeID).
+func simpanDanAntrekan(tx *gorm.DB, notification *models.Notification, data models.FCMNotificationData, now time.Time) error {
+	userID, title, body := notification.UserID, notification.Title, notification.Body
+	if err := tx.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+	pref, pengaturan, err := muatPreferensi(tx, userID)
+	if err != nil {
+		log.Printf("Failed to load notification preference for user %d: %v", userID, err)
+	}
+	status, nextAttemptAt, alasan := jadwalPush(pref, pengaturan, data, now)
+	outbox := models.NotificationOutbox{
+		NotificationID: notification.ID,
+		UserID:         userID,
+		Saluran:        SaluranPush,
+		Title:          title,
+		Body:           body,
+		Payload:        notification.Data,
+		Status:         status,
+		NextAttemptAt:  nextAttemptAt,
+		LastError:      alasan,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := tx.Create(&outbox).Error; err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	if emailDiizinkan(data) && pengaturan.aktif(kategoriPreferensi(data), SaluranEmail) {
+		email := outbox
+		email.ID = 0
+		email.Saluran = SaluranEmail
+		email.Status = OutboxStatusPending
+		email.NextAttemptAt = now
+		email.LastError = ""
+		if err := tx.Create(&email).Error; err != nil {
+			return fmt.Errorf("failed to enqueue email notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// StartNotificationOutboxWorker mengirim isi outbox secara berkala (default tiap 10
+// detik, NOTIFICATION_OUTBOX_INTERVAL) atau segera setelah dibangunkan. Push/SMS
+// dan email diproses oleh loop yang terpisah.
+func StartNotificationOutboxWorker() {
+	interval := 10 * time.Second
+	if value, err := time.ParseDuration(os.Getenv("NOTIFICATION_OUTBOX_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+	for _, antrian := range []*antrianOutbox{antrianPush, antrianEmail} {
+		go func(antrian *antrianOutbox) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				prosesOutbox(antrian, time.Now())
+				select {
+				case <-ticker.C:
+				case <-antrian.wake:
+				}
+			}
+		}(antrian)
+	}
+	log.Printf("Notification outbox worker started with interval %v", interval)
+}
+
+// ambilOutboxJatuhTempo mengklaim baris yang siap dikirim. SKIP LOCKED membuat
+// beberapa instance aplikasi tidak mengambil baris yang sama.
+func ambilOutboxJatuhTempo(antrian *antrianOutbox, now time.Time) ([]models.NotificationOutbox, error) {
+	var batch []models.NotificationOutbox
+	err := database.GetGormDBInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ? AND saluran IN ?", OutboxStatusPending, now, antrian.saluran).
+			Order("next_attempt_at asc").
+			Limit(antrian.batchSize).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(batch))
+		for i, item := range batch {
+			ids[i] = item.ID
+		}
+		return tx.Model(&models.NotificationOutbox{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(outboxLease)).Error
+	})
+	return batch, err
+}
+
+// prosesOutbox mengambil satu batch outbox milik antrian, lalu mengirim baris email
+// lewat mailer, SMS/WhatsApp lewat penyedia pesan dan push lewat FCM ke setiap
+// perangkat aktif milik user.
+func prosesOutbox(antrian *antrianOutbox, now time.Time) {
+	batch, err := ambilOutboxJatuhTempo(antrian, now)
+	if err != nil {
+		log.Printf("Failed to claim %s notification outbox: %v", antrian.nama, err)
+		return
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	userIDs := make([]uint, 0, len(batch))
+	for _, item := range batch {
+		userIDs = append(userIDs, item.UserID)
+	}
+	prefs, err := preferensiUsers(database.GetGormDBInstance(), userIDs)
+	if err != nil {
+		// Lebih aman menunda daripada mengirim isi lengkap ke user mode samaran
+		for _, item := range batch {
+			catatHasilOutbox(item, fmt.Errorf("failed to retrieve notification preferences: %w", err))
+		}
+		return
+	}
+
+	var push, email, pesanSingkat []models.NotificationOutbox
+	for _, item := range batch {
+		switch item.Saluran {
+		case SaluranEmail:
+			email = append(email, item)
+		case SaluranSMS, SaluranWhatsApp:
+			pesanSingkat = append(pesanSingkat, item)
+		default:
+			push = append(push, item)
+		}
+	}
+	if len(email) > 0 {
+		prosesOutboxEmail(email, prefs)
+	}
+	if len(pesanSingkat) > 0 {
+		prosesOutboxPesanSingkat(pesanSingkat, prefs)
+	}
+	if len(push) > 0 {
+		prosesOutboxPush(push, prefs, now)
+	}
+}
+
+func prosesOutboxPush(batch []models.NotificationOutbox, prefs map[uint]models.PreferensiNotifikasi, now time.Time) {
+	client, err := helper.GetPushProvider()
+	if err != nil {
+		for _, item := range batch {
+			catatHasilOutbox(item, err)
+		}
+		return
+	}
+
+	userIDs := make([]uint, 0, len(batch))
+	for _, item := range batch {
+		userIDs = append(userIDs, item.UserID)
+	}
+	tokens, err := perangkatAktif(database.GetGormDBInstance(), userIDs)
+	if err != nil {
+		for _, item := range batch {
+			catatHasilOutbox(item, fmt.Errorf("failed to retrieve devices: %w", err))
+		}
+		return
+	}
+
+	// pemilik[i] adalah indeks baris outbox untuk messages[i]
+	var messages []*messaging.Message
+	var pemilik []int
+	payloads := make(map[int]models.FCMNotificationData, len(batch))
+	for i, item := range batch {
+		var data models.FCMNotificationData
+		if err := json.Unmarshal([]byte(item.Payload), &data); err != nil {
+			catatHasilOutbox(item, fmt.Errorf("invalid payload: %w", err))
+			continue
+		}
+		payloads[i] = data
+		if len(tokens[item.UserID]) == 0 {
+			tanpaPerangkat(item, prefs[item.UserID], data, errTanpaNotificationToken, now)
+			continue
+		}
+		for _, token := range tokens[item.UserID] {
+			message := buildFCMMessage(data, models.Notification{Title: item.Title, Body: item.Body})
+			message.Token = token
+			if prefs[item.UserID].ModeSamaran {
+				samarkanPesan(message)
+			}
+			messages = append(messages, message)
+			pemilik = append(pemilik, i)
+		}
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	// Baris dianggap terkirim jika minimal satu perangkat berhasil. Jika semua gagal,
+	// error sementara diutamakan agar baris dicoba lagi; jika semua token tidak
+	// valid, tidak ada lagi perangkat tujuan.
+	db := database.GetGormDBInstance()
+	hasil := make(map[int]error)
+	for i, result := range client.SendBatch(context.Background(), messages) {
+		catatHasilPerangkat(db, result.Token, result.Error, now)
+
+		idx := pemilik[i]
+		err := result.Error
+		if err != nil && helper.IsInvalidFCMToken(err) {
+			err = fmt.Errorf("%w: %v", errTanpaNotificationToken, err)
+		}
+		prev, ok := hasil[idx]
+		switch {
+		case !ok, err == nil:
+			hasil[idx] = err
+		case prev != nil && errors.Is(prev, errTanpaNotificationToken):
+			hasil[idx] = err
+		}
+	}
+	for idx, err := range hasil {
+		if err != nil && errors.Is(err, errTanpaNotificationToken) {
+			tanpaPerangkat(batch[idx], prefs[batch[idx].UserID], payloads[idx], err, now)
+			continue
+		}
+		catatHasilOutbox(batch[idx], err)
+	}
+}
+
+// tanpaPerangkat menutup baris push yang tidak punya perangkat tujuan. Notifikasi
+// mendesak dialihkan ke SMS/WhatsApp agar tetap sampai ke user.
+func tanpaPerangkat(item models.NotificationOutbox, pref models.PreferensiNotifikasi, data models.FCMNotificationData, err error, now time.Time) {
+	if data.Urgent {
+		dialihkan, errCadangan := antrekanCadangan(item, pref, data, now)
+		if errCadangan != nil {
+			// Gagal mengantrekan cadangan: coba lagi baris push ini nanti
+			catatHasilOutbox(item, fmt.Errorf("failed to enqueue fallback: %w", errCadangan))
+			return
+		}
+		if dialihkan {
+			err = fmt.Errorf("%w; fallback to %s", err, saluranCadangan())
+		}
+	}
+	catatHasilOutbox(item, err)
+}
+
+// catatHasilOutbox memperbarui status baris outbox sesuai hasil pengiriman.
+func catatHasilOutbox(item models.NotificationOutbox, err error) {
+	db := database.GetGormDBInstance()
+	now := time.Now()
+
+	updates := map[string]interface{}{"attempts": item.Attempts + 1, "updated_at": now}
+	switch {
+	case err == nil:
+		updates["status"] = OutboxStatusSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case errors.Is(err, errTanpaNotificationToken), errors.Is(err, errTanpaAlamatEmail), errors.Is(err, errTanpaNomorTelepon):
+		// Tidak ada perangkat/alamat tujuan; notifikasi tetap ada di inbox.
+		updates["status"] = OutboxStatusSkipped
+		updates["last_error"] = err.Error()
+	case item.Attempts+1 >= outboxMaxAttempts():
+		updates["status"] = OutboxStatusDead
+		updates["last_error"] = err.Error()
+		log.Printf("Notification outbox %d moved to dead letter: %v", item.ID, err)
+	default:
+		updates["next_attempt_at"] = now.Add(outboxBackoff(item.Attempts + 1))
+		updates["last_error"] = err.Error()
+	}
+	if err := db.Model(&models.NotificationOutbox{}).Where("id = ?", item.ID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update notification outbox %d: %v", item.ID, err)
+	}
+}
+
+/*=========================== ADMIN: DEAD LETTER OUTBOX =======================*/
+
+func AdminGetDeadLetterNotifications(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	db := database.GetGormDBInstance()
+	query := db.Model(&models.NotificationOutbox{}).Where("status = ?", OutboxStatusDead)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to count dead letter notifications",
+		})
+	}
+	var items []models.NotificationOutbox
+	if err := query.Order("updated_at desc").Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve dead letter notifications",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Dead letter notifications retrieved successfully",
+		Data: fiber.Map{
+			"items": items,
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// AdminRetryDeadLetterNotification mengembalikan notifikasi dead letter ke antrean.
+func AdminRetryDeadLetterNotification(c *fiber.Ctx) error {
+	now := time.Now()
+	result := database.GetGormDBInstance().Model(&models.NotificationOutbox{}).
+		Where("id = ? AND status = ?", c.Params("id"), OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":          OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to requeue notification",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Dead letter notification not found",
+		})
+	}
+	bangunkanOutbox()
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification requeued",
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// siapkanOutboxTest memakai SQLite in-memory sebagai database dan penyedia memori
+// untuk push, email dan SMS, sehingga satu putaran outbox bisa dijalankan tanpa
+// MySQL, Firebase, SMTP maupun gateway SMS.
+func siapkanOutboxTest(t *testing.T) (*helper.MemoryPushProvider, *helper.MemoryMailer, *helper.FakeMessagingProvider) {
+	t.Helper()
+	siapkanDBTest(t, &models.Notification{}, &models.NotificationOutbox{}, &models.UserDevice{}, &models.PreferensiNotifikasi{})
+	push := &helper.MemoryPushProvider{}
+	mailer := &helper.MemoryMailer{}
+	sms := &helper.FakeMessagingProvider{}
+	helper.SetPushProvider(push)
+	helper.SetMailer(mailer)
+	helper.SetMessagingProvider(sms)
+	t.Cleanup(func() {
+		helper.SetPushProvider(nil)
+		helper.SetMailer(nil)
+		helper.SetMessagingProvider(nil)
+	})
+	return push, mailer, sms
+}
+
+func buatUserTest(t *testing.T, id uint, email, phone, pengaturan string, token string) {
+	t.Helper()
+	db := database.DB
+	if err := db.Exec("INSERT INTO users (id, full_name, email, phone_number, role) VALUES (?, ?, ?, ?, 'masyarakat')",
+		id, "User Test", email, phone).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pengaturan != "" {
+		pref := models.PreferensiNotifikasi{UserID: id, Pengaturan: pengaturan, ZonaWaktu: zonaWaktuDefault, Bahasa: BahasaIndonesia}
+		if err := db.Create(&pref).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if token != "" {
+		device := models.UserDevice{UserID: id, Token: token, Active: true, LastSeenAt: time.Now()}
+		if err := db.Create(&device).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func antrekanTest(t *testing.T, userID uint, data models.FCMNotificationData, now time.Time) *models.Notification {
+	t.Helper()
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		notification, err = antrekanNotifikasi(tx, userID, "Laporan selesai", "Laporan Anda telah selesai diproses", data, now)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notification
+}
+
+func statusOutbox(t *testing.T, notificationID uint, saluran string) string {
+	t.Helper()
+	var row models.NotificationOutbox
+	if err := database.DB.Where("notification_id = ? AND saluran = ?", notificationID, saluran).First(&row).Error; err != nil {
+		t.Fatalf("outbox %s for notification %d: %v", saluran, notificationID, err)
+	}
+	return row.Status
+}
+
+func TestProsesOutboxMengirimPushKePerangkatAktif(t *testing.T) {
+	push, mailer, _ := siapkanOutboxTest(t)
+	buatUserTest(t, 1, "user@example.com", "", "", "token-1")
+	now := time.Now()
+
+	data := JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-1", Status: "completed"}, 9, "", now)
+	notification := antrekanTest(t, 1, data, now)
+	prosesOutbox(antrianPush, now)
+	prosesOutbox(antrianEmail, now)
+
+	sent := push.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 push message, got %d", len(sent))
+	}
+	if sent[0].Token != "token-1" || sent[0].Data["reportId"] != "REG-1" || sent[0].Data["kind"] != "report_status" {
+		t.Errorf("unexpected push message: token=%s data=%v", sent[0].Token, sent[0].Data)
+	}
+	if got := statusOutbox(t, notification.ID, SaluranPush); got != OutboxStatusSent {
+		t.Errorf("push outbox status = %s, want %s", got, OutboxStatusSent)
+	}
+	// Email opt-in: tanpa preferensi tidak ada email yang diantrekan maupun dikirim
+	if len(mailer.Sent()) != 0 {
+		t.Errorf("expected no email without opt-in, got %d", len(mailer.Sent()))
+	}
+}
+
+func TestProsesOutboxModeSamaranDanEmailOptIn(t *testing.T) {
+	push, mailer, _ := siapkanOutboxTest(t)
+	buatUserTest(t, 2, "user2@example.com", "", `{"report_status":{"email":true}}`, "token-2")
+	if err := database.DB.Model(&models.PreferensiNotifikasi{}).Where("user_id = ?", 2).Update("mode_samaran", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	data := JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-2", Status: "completed"}, 9, "catatan rahasia", now)
+	notification := antrekanTest(t, 2, data, now)
+	prosesOutbox(antrianPush, now)
+	prosesOutbox(antrianEmail, now)
+
+	sent := push.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 push message, got %d", len(sent))
+	}
+	for _, key := range []string{"reportId", "notes", "payload", "kind", "status"} {
+		if _, ada := sent[0].Data[key]; ada {
+			t.Errorf("discreet push still contains %q", key)
+		}
+	}
+	if sent[0].Data["type"] != "general" {
+		t.Errorf("discreet push type = %q, want general", sent[0].Data["type"])
+	}
+
+	emails := mailer.Sent()
+	if len(emails) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(emails))
+	}
+	if strings.Contains(emails[0].Text, "REG-2") || strings.Contains(emails[0].Text, "selesai") {
+		t.Errorf("discreet email leaks report details: %q", emails[0].Text)
+	}
+	if got := statusOutbox(t, notification.ID, SaluranEmail); got != OutboxStatusSent {
+		t.Errorf("email outbox status = %s, want %s", got, OutboxStatusSent)
+	}
+}
+
+func TestProsesOutboxCadanganSMSTanpaPerangkat(t *testing.T) {
+	push, _, sms := siapkanOutboxTest(t)
+	buatUserTest(t, 3, "", "081234567890", `{"appointment":{"sms":true}}`, "")
+	now := time.Now()
+
+	data := JenisJanjiTemu.Data(PayloadJanjiTemu{JanjiTemuID: 7, Status: "rejected"}, 9, "", now)
+	data.Urgent = true
+	notification := antrekanTest(t, 3, data, now)
+	prosesOutbox(antrianPush, now)
+	if got := statusOutbox(t, notification.ID, SaluranPush); got != OutboxStatusSkipped {
+		t.Errorf("push outbox status = %s, want %s", got, OutboxStatusSkipped)
+	}
+	// Baris SMS cadangan diambil pada putaran berikutnya
+	prosesOutbox(antrianPush, now.Add(time.Second))
+
+	if len(push.Sent()) != 0 {
+		t.Errorf("expected no push without devices, got %d", len(push.Sent()))
+	}
+	pesan := sms.Sent()
+	if len(pesan) != 1 {
+		t.Fatalf("expected 1 SMS, got %d", len(pesan))
+	}
+	if pesan[0].To != "081234567890" || pesan[0].Channel != SaluranSMS {
+		t.Errorf("unexpected SMS: %+v", pesan[0])
+	}
+	if got := statusOutbox(t, notification.ID, SaluranSMS); got != OutboxStatusSent {
+		t.Errorf("sms outbox status = %s, want %s", got, OutboxStatusSent)
+	}
+}
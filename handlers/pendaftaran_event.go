@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+// DaftarEvent mendaftarkan masyarakat yang login ke sebuah event. Pendaftar bisa
+// menjadi sasaran pengumuman (kampanye notifikasi) untuk event tersebut.
+func DaftarEvent(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+
+	var event models.Event
+	if err := database.DB.First(&event, c.Params("id")).Error; err != nil {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Event not found",
+		})
+	}
+
+	pendaftaran := models.PendaftaranEvent{EventID: event.ID, UserID: userID, CreatedAt: time.Now()}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&pendaftaran).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mendaftar event",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Berhasil mendaftar event",
+	})
+}
+
+func BatalDaftarEvent(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	result := database.DB.Where("event_id = ? AND user_id = ?", c.Params("id"), userID).Delete(&models.PendaftaranEvent{})
+	if result.Error != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal membatalkan pendaftaran event",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Pendaftaran event tidak ditemukan",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Pendaftaran event dibatalkan",
+	})
+}
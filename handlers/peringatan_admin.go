@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Peristiwa yang dikirim ke admin sebagai peringatan.
+const (
+	PeristiwaLaporanBaru       = "laporan_baru"
+	PeristiwaLaporanDibatalkan = "laporan_dibatalkan"
+	PeristiwaJanjiTemuBaru     = "janji_temu_baru"
+	PeristiwaKeluhanChat       = "keluhan_chat"
+)
+
+var peristiwaAdmin = []string{PeristiwaLaporanBaru, PeristiwaLaporanDibatalkan, PeristiwaJanjiTemuBaru, PeristiwaKeluhanChat}
+
+// langgananEfektif adalah pengaturan satu admin untuk satu peristiwa setelah default diisi.
+type langgananEfektif struct {
+	Peristiwa         string `json:"peristiwa"`
+	Aktif             bool   `json:"aktif"`
+	Push              bool   `json:"push"`
+	KategoriKekerasan []uint `json:"kategori_kekerasan"`
+}
+
+func langgananDari(peristiwa string, row *models.LanggananNotifikasiAdmin) langgananEfektif {
+	hasil := langgananEfektif{Peristiwa: peristiwa, Aktif: true, Push: true, KategoriKekerasan: []uint{}}
+	if row == nil {
+		return hasil
+	}
+	hasil.Aktif = row.Aktif
+	hasil.Push = row.Push
+	if row.KategoriKekerasan != "" {
+		if err := json.Unmarshal([]byte(row.KategoriKekerasan), &hasil.KategoriKekerasan); err != nil {
+			log.Printf("Invalid kategori kekerasan in langganan %d: %v", row.ID, err)
+		}
+	}
+	return hasil
+}
+
+// menerima menentukan apakah admin dengan langganan ini menerima peringatan untuk
+// laporan dengan kategori tertentu (0 = peristiwa tidak terkait kategori).
+func (l langgananEfektif) menerima(kategoriID uint) bool {
+	if !l.Aktif {
+		return false
+	}
+	if kategoriID == 0 || len(l.KategoriKekerasan) == 0 {
+		return true
+	}
+	for _, id := range l.KategoriKekerasan {
+		if id == kategoriID {
+			return true
+		}
+	}
+	return false
+}
+
+// peringatanAdmin menjelaskan satu peringatan yang akan dikirim ke admin.
+type peringatanAdmin struct {
+	Peristiwa  string
+	Kunci      string // kunci template notifikasi
+	Vars       any
+	Referensi  string // no registrasi, ID janji temu atau ID laporan moderasi
+	Aktor      uint   // user yang memicu peristiwa
+	KategoriID uint   // kategori kekerasan laporan; 0 jika tidak relevan
+	Penangan   *uint  // admin yang langsung menangani (mis. konselor yang dipilih)
+	Kecuali    uint   // admin yang tidak boleh menerima (mis. admin yang dikeluhkan)
+}
+
+// penerimaPeringatan memilih admin yang berlangganan peristiwa ini. Jika ada admin
+// penangan yang berlangganan, hanya dia yang menerima.
+func penerimaPeringatan(db *gorm.DB, p peringatanAdmin) (map[uint]langgananEfektif, error) {
+	var adminIDs []uint
+	if err := db.Model(&models.User{}).Where("role = ?", "admin").Pluck("id", &adminIDs).Error; err != nil {
+		return nil, err
+	}
+	var rows []models.LanggananNotifikasiAdmin
+	if err := db.Where("peristiwa = ?", p.Peristiwa).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	perAdmin := make(map[uint]*models.LanggananNotifikasiAdmin, len(rows))
+	for i := range rows {
+		perAdmin[rows[i].AdminID] = &rows[i]
+	}
+
+	penerima := make(map[uint]langgananEfektif)
+	for _, adminID := range adminIDs {
+		langganan := langgananDari(p.Peristiwa, perAdmin[adminID])
+		if adminID != p.Kecuali && langganan.menerima(p.KategoriID) {
+			penerima[adminID] = langganan
+		}
+	}
+	if p.Penangan != nil {
+		if langganan, ok := penerima[*p.Penangan]; ok {
+			return map[uint]langgananEfektif{*p.Penangan: langganan}, nil
+		}
+	}
+	return penerima, nil
+}
+
+// peringatkanAdmin menyimpan peringatan di inbox setiap admin penerima dan
+// mengantrekan push bagi yang mengaktifkannya.
+func peringatkanAdmin(db *gorm.DB, p peringatanAdmin, now time.Time) error {
+	penerima, err := penerimaPeringatan(db, p)
+	if err != nil {
+		return fmt.Errorf("failed to resolve admin recipients: %w", err)
+	}
+	if len(penerima) == 0 {
+		return nil
+	}
+	data := JenisPeringatanAdmin.Data(PayloadPeringatanAdmin{Peristiwa: p.Peristiwa, Referensi: p.Referensi}, p.Aktor, "", now)
+
+	var notifications []*models.Notification
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for adminID, langganan := range penerima {
+			judul, isi, err := renderNotifikasi(tx, p.Kunci, adminID, p.Vars)
+			if err != nil {
+				return err
+			}
+			notification, err := NewNotificationFromFCMData(adminID, judul, isi, data, now)
+			if err != nil {
+				return err
+			}
+			if langganan.Push {
+				err = simpanDanAntrekan(tx, notification, data, now)
+			} else {
+				err = tx.Create(notification).Error
+			}
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, notification)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	notifikasiTersimpan(notifications...)
+	return nil
+}
+
+/*=========================== ADMIN: LANGGANAN NOTIFIKASI =======================*/
+
+func AdminGetLanggananNotifikasi(c *fiber.Ctx) error {
+	adminID, _, _ := currentUserClaims(c)
+
+	var rows []models.LanggananNotifikasiAdmin
+	if err := database.GetGormDBInstance().Where("admin_id = ?", adminID).Find(&rows).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve notification subscriptions",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	perPeristiwa := make(map[string]*models.LanggananNotifikasiAdmin, len(rows))
+	for i := range rows {
+		perPeristiwa[rows[i].Peristiwa] = &rows[i]
+	}
+
+	langganan := make([]langgananEfektif, 0, len(peristiwaAdmin))
+	for _, peristiwa := range peristiwaAdmin {
+		langganan = append(langganan, langgananDari(peristiwa, perPeristiwa[peristiwa]))
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification subscriptions retrieved successfully",
+		Data:    langganan,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+type updateLanggananRequest struct {
+	Aktif             *bool   `json:"aktif"`
+	Push              *bool   `json:"push"`
+	KategoriKekerasan *[]uint `json:"kategori_kekerasan"`
+}
+
+// AdminUpdateLanggananNotifikasi mengubah langganan admin untuk satu peristiwa; field
+// yang tidak dikirim tetap.
+func AdminUpdateLanggananNotifikasi(c *fiber.Ctx) error {
+	adminID, _, _ := currentUserClaims(c)
+	peristiwa := c.Params("peristiwa")
+	if !berisi(peristiwaAdmin, peristiwa) {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Peristiwa harus salah satu dari: laporan_baru, laporan_dibatalkan, janji_temu_baru, keluhan_chat",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	var req updateLanggananRequest
+	if err := c.BodyParser(&req); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid request body",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	db := database.GetGormDBInstance()
+	if req.KategoriKekerasan != nil && len(*req.KategoriKekerasan) > 0 {
+		var jumlah int64
+		if err := db.Model(&models.ViolenceCategory{}).Where("id IN ?", *req.KategoriKekerasan).Count(&jumlah).Error; err != nil || int(jumlah) != len(*req.KategoriKekerasan) {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Kategori kekerasan tidak ditemukan",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+	}
+
+	row := models.LanggananNotifikasiAdmin{AdminID: adminID, Peristiwa: peristiwa, Aktif: true, Push: true, KategoriKekerasan: "[]"}
+	if err := db.Where("admin_id = ? AND peristiwa = ?", adminID, peristiwa).First(&row).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve notification subscription",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if req.Aktif != nil {
+		row.Aktif = *req.Aktif
+	}
+	if req.Push != nil {
+		row.Push = *req.Push
+	}
+	if req.KategoriKekerasan != nil {
+		raw, _ := json.Marshal(*req.KategoriKekerasan)
+		row.KategoriKekerasan = string(raw)
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "admin_id"}, {Name: "peristiwa"}},
+		DoUpdates: clause.AssignmentColumns([]string{"aktif", "push", "kategori_kekerasan", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to save notification subscription",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification subscription updated successfully",
+		Data:    langgananDari(peristiwa, &row),
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
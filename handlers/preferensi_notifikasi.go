@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	_ "time/tzdata" // zona waktu tetap tersedia walau image tidak punya tzdata
+
+	"firebase.google.com/go/messaging"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	SaluranPush  = "push"
+	SaluranEmail = "email"
+	// SaluranSMS di preferensi berlaku untuk cadangan SMS maupun WhatsApp.
+	SaluranSMS      = "sms"
+	SaluranWhatsApp = "whatsapp"
+)
+
+const zonaWaktuDefault = "Asia/Jakarta"
+
+// tipePreferensi adalah tipe notifikasi yang bisa diatur user; admin_alert hanya
+// diterima admin.
+var tipePreferensi = []string{"report_status", "tracking_update", "appointment", "chat", "announcement", "admin_alert"}
+
+var saluranPreferensi = []string{SaluranPush, SaluranEmail, SaluranSMS}
+
+// pengaturanNotifikasi: tipe -> saluran -> aktif.
+type pengaturanNotifikasi map[string]map[string]bool
+
+// kategoriPreferensi memetakan notifikasi ke tipe yang diatur user. Notifikasi
+// moderasi chat ikut pengaturan "chat"; tanda terima keluhan dikirim dengan type
+// report_status sehingga dikenali dari kind-nya.
+func kategoriPreferensi(data models.FCMNotificationData) string {
+	if data.Type == JenisPeringatanChat.Tipe || data.Kind == JenisKeluhanChat.nama() {
+		return "chat"
+	}
+	return data.Type
+}
+
+// saluranDefault adalah nilai saluran yang belum pernah diatur user. Email dan SMS
+// bersifat opt-in: kotak masuk atau HP bisa dipakai bersama atau dipantau pelaku,
+// jadi tidak boleh menerima apa pun sebelum user sendiri mengaktifkannya.
+var saluranDefault = map[string]bool{
+	SaluranPush:  true,
+	SaluranEmail: false,
+	SaluranSMS:   false,
+}
+
+// aktif menerima tipe preferensi, bukan type notifikasi; lihat kategoriPreferensi.
+func (p pengaturanNotifikasi) aktif(tipe, saluran string) bool {
+	if aktif, ok := p[tipe][saluran]; ok {
+		return aktif
+	}
+	return saluranDefault[saluran]
+}
+
+// lengkap mengisi tipe/saluran yang belum diatur dengan nilai default.
+func (p pengaturanNotifikasi) lengkap() pengaturanNotifikasi {
+	hasil := pengaturanNotifikasi{}
+	for _, tipe := range tipePreferensi {
+		hasil[tipe] = map[string]bool{}
+		for _, saluran := range saluranPreferensi {
+			hasil[tipe][saluran] = p.aktif(tipe, saluran)
+		}
+	}
+	return hasil
+}
+
+// muatPreferensi mengambil preferensi user; user yang belum mengatur apa pun
+// mendapat preferensi default tanpa baris baru di database.
+func muatPreferensi(db *gorm.DB, userID uint) (models.PreferensiNotifikasi, pengaturanNotifikasi, error) {
+	pref := models.PreferensiNotifikasi{UserID: userID, ZonaWaktu: zonaWaktuDefault, Bahasa: BahasaIndonesia}
+	if err := db.Where("user_id = ?", userID).First(&pref).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return pref, pengaturanNotifikasi{}, err
+	}
+	pengaturan, err := pengaturanDari(pref)
+	return pref, pengaturan, err
+}
+
+func pengaturanDari(pref models.PreferensiNotifikasi) (pengaturanNotifikasi, error) {
+	pengaturan := pengaturanNotifikasi{}
+	if pref.Pengaturan != "" {
+		if err := json.Unmarshal([]byte(pref.Pengaturan), &pengaturan); err != nil {
+			return pengaturanNotifikasi{}, err
+		}
+	}
+	return pengaturan, nil
+}
+
+func parseJam(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// akhirJamTenang mengembalikan waktu selesainya jam tenang jika now berada di
+// dalamnya. Jam tenang boleh melewati tengah malam, misalnya 22:00-06:00.
+func akhirJamTenang(pref models.PreferensiNotifikasi, now time.Time) (time.Time, bool) {
+	if pref.JamTenangMulai == "" || pref.JamTenangSelesai == "" {
+		return time.Time{}, false
+	}
+	mulai, err := parseJam(pref.JamTenangMulai)
+	if err != nil {
+		return time.Time{}, false
+	}
+	selesai, err := parseJam(pref.JamTenangSelesai)
+	if err != nil || mulai == selesai {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(pref.ZonaWaktu)
+	if err != nil {
+		loc, _ = time.LoadLocation(zonaWaktuDefault)
+	}
+
+	lokal := now.In(loc)
+	tengahMalam := time.Date(lokal.Year(), lokal.Month(), lokal.Day(), 0, 0, 0, 0, loc)
+	jam := lokal.Sub(tengahMalam)
+	switch {
+	case mulai < selesai && jam >= mulai && jam < selesai:
+		return tengahMalam.Add(selesai), true
+	case mulai > selesai && jam >= mulai:
+		return tengahMalam.AddDate(0, 0, 1).Add(selesai), true
+	case mulai > selesai && jam < selesai:
+		return tengahMalam.Add(selesai), true
+	}
+	return time.Time{}, false
+}
+
+// jadwalPush menentukan status awal baris outbox sesuai preferensi user: dilewati
+// jika push dimatikan, atau ditunda sampai jam tenang selesai jika tidak mendesak.
+func jadwalPush(pref models.PreferensiNotifikasi, pengaturan pengaturanNotifikasi, data models.FCMNotificationData, now time.Time) (status string, nextAttemptAt time.Time, alasan string) {
+	if !pengaturan.aktif(kategoriPreferensi(data), SaluranPush) {
+		return OutboxStatusSkipped, now, "push disabled by user preference"
+	}
+	if !data.Urgent {
+		if akhir, ok := akhirJamTenang(pref, now); ok {
+			return OutboxStatusPending, akhir, ""
+		}
+	}
+	return OutboxStatusPending, now, ""
+}
+
+// preferensiUsers mengambil preferensi beberapa user sekaligus; user tanpa baris
+// preferensi mendapat nilai default.
+func preferensiUsers(db *gorm.DB, userIDs []uint) (map[uint]models.PreferensiNotifikasi, error) {
+	var daftar []models.PreferensiNotifikasi
+	if err := db.Where("user_id IN ?", userIDs).Find(&daftar).Error; err != nil {
+		return nil, err
+	}
+	hasil := make(map[uint]models.PreferensiNotifikasi, len(userIDs))
+	for _, userID := range userIDs {
+		hasil[userID] = models.PreferensiNotifikasi{UserID: userID, ZonaWaktu: zonaWaktuDefault, Bahasa: BahasaIndonesia}
+	}
+	for _, pref := range daftar {
+		hasil[pref.UserID] = pref
+	}
+	return hasil, nil
+}
+
+// samarkanPesan mengganti judul/isi push dengan teks netral dan membuang data yang
+// bisa menunjukkan adanya laporan kepada orang lain yang melihat layar HP. Tipe
+// dan status juga dinetralkan karena "report_status"/"completed" sudah cukup
+// membocorkan isi notifikasi.
+func samarkanPesan(message *messaging.Message) {
+	message.Notification = &messaging.Notification{
+		Title: "Pemberitahuan baru",
+		Body:  "Buka aplikasi untuk melihat pemberitahuan Anda.",
+	}
+	message.Data["type"] = "general"
+	delete(message.Data, "status")
+	delete(message.Data, "version")
+	delete(message.Data, "reportId")
+	delete(message.Data, "notes")
+	delete(message.Data, "imageUrl")
+	delete(message.Data, "payload")
+	delete(message.Data, "kind")
+	message.Data["deepLink"] = "laporanku://notifications"
+	message.Data["discreet"] = "true"
+}
+
+type preferensiNotifikasiResponse struct {
+	Pengaturan       pengaturanNotifikasi `json:"pengaturan"`
+	JamTenangMulai   string               `json:"jam_tenang_mulai"`
+	JamTenangSelesai string               `json:"jam_tenang_selesai"`
+	ZonaWaktu        string               `json:"zona_waktu"`
+	ModeSamaran      bool                 `json:"mode_samaran"`
+	Bahasa           string               `json:"bahasa"`
+	Tipe             []string             `json:"tipe"`
+	Saluran          []string             `json:"saluran"`
+}
+
+func toPreferensiResponse(pref models.PreferensiNotifikasi, pengaturan pengaturanNotifikasi) preferensiNotifikasiResponse {
+	return preferensiNotifikasiResponse{
+		Pengaturan:       pengaturan.lengkap(),
+		JamTenangMulai:   pref.JamTenangMulai,
+		JamTenangSelesai: pref.JamTenangSelesai,
+		ZonaWaktu:        pref.ZonaWaktu,
+		ModeSamaran:      pref.ModeSamaran,
+		Bahasa:           pref.Bahasa,
+		Tipe:             tipePreferensi,
+		Saluran:          saluranPreferensi,
+	}
+}
+
+func GetPreferensiNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	pref, pengaturan, err := muatPreferensi(database.GetGormDBInstance(), userID)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve notification preferences",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification preferences",
+		Data:    toPreferensiResponse(pref, pengaturan),
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+type updatePreferensiRequest struct {
+	Pengaturan       pengaturanNotifikasi `json:"pengaturan"`
+	JamTenangMulai   *string              `json:"jam_tenang_mulai"`
+	JamTenangSelesai *string              `json:"jam_tenang_selesai"`
+	ZonaWaktu        *string              `json:"zona_waktu"`
+	ModeSamaran      *bool                `json:"mode_samaran"`
+	Bahasa           *string              `json:"bahasa"`
+}
+
+func berisi(daftar []string, value string) bool {
+	for _, item := range daftar {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validasiPreferensi(req updatePreferensiRequest, pref *models.PreferensiNotifikasi) error {
+	for tipe, saluran := range req.Pengaturan {
+		if !berisi(tipePreferensi, tipe) {
+			return fmt.Errorf("Tipe notifikasi tidak dikenal: %s", tipe)
+		}
+		for nama := range saluran {
+			if !berisi(saluranPreferensi, nama) {
+				return fmt.Errorf("Saluran notifikasi tidak dikenal: %s", nama)
+			}
+		}
+	}
+	if req.JamTenangMulai != nil {
+		pref.JamTenangMulai = *req.JamTenangMulai
+	}
+	if req.JamTenangSelesai != nil {
+		pref.JamTenangSelesai = *req.JamTenangSelesai
+	}
+	if (pref.JamTenangMulai == "") != (pref.JamTenangSelesai == "") {
+		return errors.New("Jam tenang mulai dan selesai harus diisi bersamaan")
+	}
+	if pref.JamTenangMulai != "" {
+		if _, err := parseJam(pref.JamTenangMulai); err != nil {
+			return errors.New("Format jam tenang harus HH:MM")
+		}
+		if _, err := parseJam(pref.JamTenangSelesai); err != nil {
+			return errors.New("Format jam tenang harus HH:MM")
+		}
+	}
+	if req.ZonaWaktu != nil {
+		if _, err := time.LoadLocation(*req.ZonaWaktu); err != nil || *req.ZonaWaktu == "" {
+			return errors.New("Zona waktu tidak valid")
+		}
+		pref.ZonaWaktu = *req.ZonaWaktu
+	}
+	if req.ModeSamaran != nil {
+		pref.ModeSamaran = *req.ModeSamaran
+	}
+	if req.Bahasa != nil {
+		if !berisi(bahasaDidukung, *req.Bahasa) {
+			return errors.New("Bahasa harus salah satu dari: id, en")
+		}
+		pref.Bahasa = *req.Bahasa
+	}
+	return nil
+}
+
+// UpdatePreferensiNotifikasi menyimpan perubahan preferensi. Hanya tipe/saluran yang
+// dikirim yang diubah; sisanya tetap.
+func UpdatePreferensiNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+
+	var req updatePreferensiRequest
+	if err := c.BodyParser(&req); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid request body",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	db := database.GetGormDBInstance()
+	pref, pengaturan, err := muatPreferensi(db, userID)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve notification preferences",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if err := validasiPreferensi(req, &pref); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: err.Error(),
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	for tipe, saluran := range req.Pengaturan {
+		if pengaturan[tipe] == nil {
+			pengaturan[tipe] = map[string]bool{}
+		}
+		for nama, aktif := range saluran {
+			pengaturan[tipe][nama] = aktif
+		}
+	}
+	raw, err := json.Marshal(pengaturan)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to encode notification preferences",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	pref.Pengaturan = string(raw)
+
+	if err := db.Save(&pref).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to save notification preferences",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Notification preferences updated",
+		Data:    toPreferensiResponse(pref, pengaturan),
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
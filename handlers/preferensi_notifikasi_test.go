@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKategoriPreferensiTandaTerimaKeluhan(t *testing.T) {
+	now := time.Now()
+	kasus := []struct {
+		nama string
+		data models.FCMNotificationData
+		want string
+	}{
+		{"tanda terima keluhan", JenisKeluhanChat.Data(PayloadModerasiChat{ReportID: "7"}, 1, "", now), "chat"},
+		{"peringatan chat", JenisPeringatanChat.Data(PayloadModerasiChat{ReportID: "7"}, 1, "", now), "chat"},
+		{"status laporan", JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-1"}, 1, "", now), "report_status"},
+		{"janji temu", JenisJanjiTemu.Data(PayloadJanjiTemu{JanjiTemuID: 1}, 1, "", now), "appointment"},
+	}
+	for _, k := range kasus {
+		if got := kategoriPreferensi(k.data); got != k.want {
+			t.Errorf("%s: kategori = %q, want %q", k.nama, got, k.want)
+		}
+	}
+
+	// Tanda terima keluhan mengikuti pengaturan chat, bukan report_status
+	pengaturan := pengaturanNotifikasi{"chat": {SaluranPush: false}, "report_status": {SaluranPush: true}}
+	pref := models.PreferensiNotifikasi{ZonaWaktu: zonaWaktuDefault}
+	if status, _, _ := jadwalPush(pref, pengaturan, kasus[0].data, now); status != OutboxStatusSkipped {
+		t.Errorf("receipt with chat push disabled: status = %s, want %s", status, OutboxStatusSkipped)
+	}
+	if status, _, _ := jadwalPush(pref, pengaturan, kasus[2].data, now); status != OutboxStatusPending {
+		t.Errorf("report status with push enabled: status = %s, want %s", status, OutboxStatusPending)
+	}
+}
+
+func TestAkhirJamTenang(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	pref := models.PreferensiNotifikasi{JamTenangMulai: "22:00", JamTenangSelesai: "06:00", ZonaWaktu: "Asia/Jakarta"}
+	kasus := []struct {
+		nama   string
+		now    time.Time
+		tenang bool
+		akhir  time.Time
+	}{
+		{"sebelum jam tenang", time.Date(2030, 1, 7, 21, 59, 0, 0, wib), false, time.Time{}},
+		{"malam hari", time.Date(2030, 1, 7, 23, 0, 0, 0, wib), true, time.Date(2030, 1, 8, 6, 0, 0, 0, wib)},
+		{"dini hari", time.Date(2030, 1, 8, 2, 0, 0, 0, wib), true, time.Date(2030, 1, 8, 6, 0, 0, 0, wib)},
+		{"tepat selesai", time.Date(2030, 1, 8, 6, 0, 0, 0, wib), false, time.Time{}},
+		// 16:00 UTC = 23:00 WIB; zona waktu user yang dipakai, bukan zona server
+		{"zona waktu user", time.Date(2030, 1, 7, 16, 0, 0, 0, time.UTC), true, time.Date(2030, 1, 8, 6, 0, 0, 0, wib)},
+	}
+	for _, k := range kasus {
+		akhir, tenang := akhirJamTenang(pref, k.now)
+		if tenang != k.tenang || (tenang && !akhir.Equal(k.akhir)) {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", k.nama, akhir, tenang, k.akhir, k.tenang)
+		}
+	}
+
+	siang := models.PreferensiNotifikasi{JamTenangMulai: "12:00", JamTenangSelesai: "13:00", ZonaWaktu: "Asia/Jakarta"}
+	if akhir, tenang := akhirJamTenang(siang, time.Date(2030, 1, 7, 12, 30, 0, 0, wib)); !tenang || !akhir.Equal(time.Date(2030, 1, 7, 13, 0, 0, 0, wib)) {
+		t.Errorf("same-day quiet hours: got (%v, %v)", akhir, tenang)
+	}
+	if _, tenang := akhirJamTenang(models.PreferensiNotifikasi{ZonaWaktu: "Asia/Jakarta"}, time.Date(2030, 1, 7, 23, 0, 0, 0, wib)); tenang {
+		t.Error("no quiet hours configured but reported as quiet")
+	}
+}
+
+func TestJadwalPushMengikutiPreferensi(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	now := time.Date(2030, 1, 7, 23, 0, 0, 0, wib)
+	pref := models.PreferensiNotifikasi{JamTenangMulai: "22:00", JamTenangSelesai: "06:00", ZonaWaktu: "Asia/Jakarta"}
+	laporan := JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-1"}, 1, "", now)
+
+	status, next, _ := jadwalPush(pref, pengaturanNotifikasi{}, laporan, now)
+	if status != OutboxStatusPending || !next.Equal(time.Date(2030, 1, 8, 6, 0, 0, 0, wib)) {
+		t.Errorf("quiet hours: got (%s, %v), want pending until 06:00", status, next)
+	}
+
+	mendesak := laporan
+	mendesak.Urgent = true
+	if status, next, _ := jadwalPush(pref, pengaturanNotifikasi{}, mendesak, now); status != OutboxStatusPending || !next.Equal(now) {
+		t.Errorf("urgent during quiet hours: got (%s, %v), want pending now", status, next)
+	}
+
+	mati := pengaturanNotifikasi{"report_status": {SaluranPush: false}}
+	if status, _, alasan := jadwalPush(pref, mati, mendesak, now); status != OutboxStatusSkipped || alasan == "" {
+		t.Errorf("push disabled: got (%s, %q), want skipped with reason", status, alasan)
+	}
+}
+
+func TestPengaturanDefault(t *testing.T) {
+	lengkap := pengaturanNotifikasi{"appointment": {SaluranSMS: true}}.lengkap()
+	for _, tipe := range tipePreferensi {
+		if !lengkap[tipe][SaluranPush] {
+			t.Errorf("%s push default = false, want true", tipe)
+		}
+		if lengkap[tipe][SaluranEmail] {
+			t.Errorf("%s email default = true, want opt-in", tipe)
+		}
+	}
+	if !lengkap["appointment"][SaluranSMS] || lengkap["report_status"][SaluranSMS] {
+		t.Errorf("sms: appointment=%v report_status=%v, want only the opted-in type", lengkap["appointment"][SaluranSMS], lengkap["report_status"][SaluranSMS])
+	}
+}
+
+func TestProsesOutboxMenundaPushSaatJamTenang(t *testing.T) {
+	push, _, _ := siapkanOutboxTest(t)
+	buatUserTest(t, 4, "user4@example.com", "", "", "token-4")
+	pref := models.PreferensiNotifikasi{UserID: 4, ZonaWaktu: "Asia/Jakarta", JamTenangMulai: "22:00", JamTenangSelesai: "06:00", Bahasa: BahasaIndonesia}
+	if err := database.DB.Create(&pref).Error; err != nil {
+		t.Fatal(err)
+	}
+	wib := time.FixedZone("WIB", 7*3600)
+	now := time.Date(2030, 1, 7, 23, 0, 0, 0, wib)
+
+	data := JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-4", Status: "completed"}, 9, "", now)
+	notification := antrekanTest(t, 4, data, now)
+	prosesOutbox(antrianPush, now)
+	if len(push.Sent()) != 0 {
+		t.Fatalf("push sent during quiet hours")
+	}
+	if got := statusOutbox(t, notification.ID, SaluranPush); got != OutboxStatusPending {
+		t.Errorf("push outbox status = %s, want %s", got, OutboxStatusPending)
+	}
+
+	prosesOutbox(antrianPush, time.Date(2030, 1, 8, 6, 0, 0, 0, wib))
+	if len(push.Sent()) != 1 {
+		t.Errorf("expected push after quiet hours, got %d", len(push.Sent()))
+	}
+}
+
+func TestSamarkanPesan(t *testing.T) {
+	now := time.Now()
+	data := JenisStatusLaporan.Data(PayloadLaporan{NoRegistrasi: "REG-9", Status: "completed"}, 3, "Pelaku sudah ditangkap", now)
+	data.ImageURL = "https://example.com/bukti.jpg"
+	message := buildFCMMessage(data, models.Notification{Title: "Laporan REG-9 selesai", Body: "Pelaku sudah ditangkap"})
+	message.Token = "token-9"
+
+	samarkanPesan(message)
+
+	if message.Notification.Title != "Pemberitahuan baru" || strings.Contains(message.Notification.Body, "REG-9") {
+		t.Errorf("discreet notification text leaks details: %+v", message.Notification)
+	}
+	for key, value := range message.Data {
+		if strings.Contains(value, "REG-9") || strings.Contains(value, "completed") || strings.Contains(value, "report") {
+			t.Errorf("discreet data %q leaks details: %q", key, value)
+		}
+	}
+	if message.Data["type"] != "general" || message.Data["discreet"] != "true" || message.Data["deepLink"] != "laporanku://notifications" {
+		t.Errorf("unexpected discreet data: %v", message.Data)
+	}
+	if message.Token != "token-9" {
+		t.Errorf("token changed to %q", message.Token)
+	}
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var errBukanKonselor = errors.New("konselor tidak ditemukan atau tidak aktif")
+
+// konselorPublik adalah bentuk profil yang aman ditampilkan tanpa login; tidak
+// memuat data akun seperti email atau nomor telepon.
+type konselorPublik struct {
+	KonselorID   uint     `json:"konselor_id"`
+	Nama         string   `json:"nama"`
+	Spesialisasi []string `json:"spesialisasi"`
+	Bahasa       []string `json:"bahasa"`
+	JenisKelamin string   `json:"jenis_kelamin"`
+	Bio          string   `json:"bio"`
+	Foto         string   `json:"foto"`
+}
+
+func pisahDaftar(value string) []string {
+	hasil := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			hasil = append(hasil, item)
+		}
+	}
+	return hasil
+}
+
+// normalisasiDaftar merapikan input "Trauma, anak" menjadi "trauma,anak" agar bisa
+// dicari dengan FIND_IN_SET.
+func normalisasiDaftar(value string) string {
+	daftar := pisahDaftar(strings.ToLower(value))
+	return strings.Join(daftar, ",")
+}
+
+func toKonselorPublik(profil models.ProfilKonselor) konselorPublik {
+	nama := profil.NamaTampilan
+	if nama == "" {
+		nama = profil.User.FullName
+	}
+	return konselorPublik{
+		KonselorID:   profil.UserID,
+		Nama:         nama,
+		Spesialisasi: pisahDaftar(profil.Spesialisasi),
+		Bahasa:       pisahDaftar(profil.Bahasa),
+		JenisKelamin: profil.JenisKelamin,
+		Bio:          profil.Bio,
+		Foto:         profil.Foto,
+	}
+}
+
+// jadwalKonselorPublik adalah slot jadwal beserta profil publik konselornya, tanpa
+// data akun admin.
+type jadwalKonselorPublik struct {
+	ID           uint           `json:"id"`
+	KonselorID   uint           `json:"konselor_id"`
+	Konselor     konselorPublik `json:"konselor"`
+	WaktuMulai   time.Time      `json:"waktu_mulai"`
+	WaktuSelesai time.Time      `json:"waktu_selesai"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// toJadwalKonselorPublik memuat profil konselor untuk setiap slot. Admin yang belum
+// punya profil konselor ditampilkan dengan nama lengkapnya saja.
+func toJadwalKonselorPublik(tx *gorm.DB, jadwal []models.JadwalKonselor) ([]jadwalKonselorPublik, error) {
+	konselorIDs := make([]uint, 0, len(jadwal))
+	for _, j := range jadwal {
+		konselorIDs = append(konselorIDs, j.KonselorID)
+	}
+	konselor := map[uint]konselorPublik{}
+	if len(konselorIDs) > 0 {
+		var profil []models.ProfilKonselor
+		if err := tx.Preload("User").Where("user_id IN ?", konselorIDs).Find(&profil).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range profil {
+			konselor[p.UserID] = toKonselorPublik(p)
+		}
+		var users []models.User
+		if err := tx.Select("id", "full_name").Where("id IN ?", konselorIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if _, ada := konselor[u.ID]; !ada {
+				konselor[u.ID] = konselorPublik{KonselorID: u.ID, Nama: u.FullName, Spesialisasi: []string{}, Bahasa: []string{}}
+			}
+		}
+	}
+
+	daftar := make([]jadwalKonselorPublik, 0, len(jadwal))
+	for _, j := range jadwal {
+		daftar = append(daftar, jadwalKonselorPublik{
+			ID:           j.ID,
+			KonselorID:   j.KonselorID,
+			Konselor:     konselor[j.KonselorID],
+			WaktuMulai:   j.WaktuMulai,
+			WaktuSelesai: j.WaktuSelesai,
+			CreatedAt:    j.CreatedAt,
+			UpdatedAt:    j.UpdatedAt,
+		})
+	}
+	return daftar, nil
+}
+
+// cekKonselorAktif memastikan user yang dipilih masyarakat adalah admin dengan
+// profil konselor aktif.
+func cekKonselorAktif(tx *gorm.DB, konselorID uint) error {
+	var count int64
+	if err := tx.Model(&models.ProfilKonselor{}).
+		Joins("JOIN users ON users.id = profil_konselors.user_id").
+		Where("profil_konselors.user_id = ? AND profil_konselors.aktif = ? AND users.role = ?", konselorID, true, "admin").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errBukanKonselor
+	}
+	return nil
+}
+
+/*=========================== PUBLIK: DIREKTORI KONSELOR =======================*/
+
+func GetDaftarKonselor(c *fiber.Ctx) error {
+	query := database.DB.Preload("User").Where("aktif = ?", true).Order("nama_tampilan asc")
+	if spesialisasi := c.Query("spesialisasi"); spesialisasi != "" {
+		query = query.Where("FIND_IN_SET(?, spesialisasi)", strings.ToLower(strings.TrimSpace(spesialisasi)))
+	}
+	if bahasa := c.Query("bahasa"); bahasa != "" {
+		query = query.Where("FIND_IN_SET(?, bahasa)", strings.ToLower(strings.TrimSpace(bahasa)))
+	}
+	if jenisKelamin := c.Query("jenis_kelamin"); jenisKelamin != "" {
+		query = query.Where("jenis_kelamin = ?", jenisKelamin)
+	}
+
+	var profil []models.ProfilKonselor
+	if err := query.Find(&profil).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve konselor",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	daftar := make([]konselorPublik, 0, len(profil))
+	for _, p := range profil {
+		daftar = append(daftar, toKonselorPublik(p))
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "List of konselor",
+		Data:    daftar,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func GetDetailKonselor(c *fiber.Ctx) error {
+	var profil models.ProfilKonselor
+	if err := database.DB.Preload("User").Where("user_id = ? AND aktif = ?", c.Params("id"), true).First(&profil).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Konselor not found",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Konselor detail",
+		Data:    toKonselorPublik(profil),
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+/*=========================== ADMIN: KELOLA PROFIL KONSELOR =======================*/
+
+func AdminGetProfilKonselor(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid user ID",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		userID = uint(id)
+	}
+
+	var profil models.ProfilKonselor
+	if err := database.DB.Where("user_id = ?", userID).First(&profil).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Profil konselor belum dibuat",
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Profil konselor",
+		Data:    profil,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// AdminSimpanProfilKonselor membuat atau memperbarui profil konselor. Tanpa
+// user_id, profil yang disimpan adalah milik admin yang login.
+func AdminSimpanProfilKonselor(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	if value := c.FormValue("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusBadRequest,
+				Status:  "error",
+				Message: "Invalid user ID",
+			}
+			return c.Status(http.StatusBadRequest).JSON(response)
+		}
+		userID = uint(id)
+	}
+
+	var user models.User
+	if err := database.DB.Select("id", "role", "full_name").First(&user, userID).Error; err != nil || user.Role != "admin" {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Profil konselor hanya bisa dibuat untuk user admin",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	var profil models.ProfilKonselor
+	err := database.DB.Where("user_id = ?", userID).First(&profil).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve profil konselor",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	if profil.ID == 0 {
+		profil = models.ProfilKonselor{UserID: userID, NamaTampilan: user.FullName, Aktif: true}
+	}
+
+	if nama := c.FormValue("nama_tampilan"); nama != "" {
+		profil.NamaTampilan = nama
+	}
+	if spesialisasi := c.FormValue("spesialisasi"); spesialisasi != "" {
+		profil.Spesialisasi = normalisasiDaftar(spesialisasi)
+	}
+	if bahasa := c.FormValue("bahasa"); bahasa != "" {
+		profil.Bahasa = normalisasiDaftar(bahasa)
+	}
+	if jenisKelamin := c.FormValue("jenis_kelamin"); jenisKelamin != "" {
+		profil.JenisKelamin = jenisKelamin
+	}
+	if bio := c.FormValue("bio"); bio != "" {
+		profil.Bio = bio
+	}
+	if aktif := c.FormValue("aktif"); aktif != "" {
+		profil.Aktif = aktif == "true" || aktif == "1"
+	}
+
+	if file, err := c.FormFile("foto"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusInternalServerError,
+				Status:  "error",
+				Message: "Gagal Membuka File Foto",
+			}
+			return c.Status(http.StatusInternalServerError).JSON(response)
+		}
+		defer src.Close()
+
+		fotoURL, err := helper.UploadFileToCloudinary(src, file.Filename)
+		if err != nil {
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusInternalServerError,
+				Status:  "error",
+				Message: "Gagal Mengupload Foto",
+			}
+			return c.Status(http.StatusInternalServerError).JSON(response)
+		}
+		profil.Foto = fotoURL
+	}
+
+	if err := database.DB.Omit("User").Save(&profil).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menyimpan profil konselor",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Profil konselor berhasil disimpan",
+		Data:    profil,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// Jenis event pada stream realtime.
+const (
+	EventNotifikasiBaru    = "notification"
+	EventJumlahBelumDibaca = "unread_count"
+	EventLaporanBaru       = "laporan_baru"
+	EventStatusLaporan     = "laporan_status"
+	// EventSesiBerakhir dikirim tepat sebelum stream ditutup karena JWT login habis.
+	EventSesiBerakhir = "session_expired"
+)
+
+// topikAdmin diterima oleh semua admin yang sedang membuka stream.
+const topikAdmin = "admin"
+
+// streamHeartbeat menjaga koneksi tetap hidup melewati proxy yang menutup koneksi diam.
+const streamHeartbeat = 25 * time.Second
+
+// streamTokenTTL: token stream hanya dipakai untuk membuka koneksi, jadi cukup singkat.
+const streamTokenTTL = time.Minute
+
+func topikUser(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// notifikasiTersimpan dipanggil setelah transaksi yang membuat notifikasi berhasil
+// commit: membangunkan worker outbox lalu mengirim event ke stream penerima.
+func notifikasiTersimpan(notifications ...*models.Notification) {
+	bangunkanOutbox()
+
+	hub := helper.GetRealtimeHub()
+	var userIDs []uint
+	for _, notification := range notifications {
+		if notification == nil {
+			continue
+		}
+		hub.Publish(topikUser(notification.UserID), helper.RealtimeEvent{Type: EventNotifikasiBaru, Data: notification})
+		userIDs = append(userIDs, notification.UserID)
+	}
+	terbitkanJumlahBelumDibaca(database.GetGormDBInstance(), userIDs...)
+}
+
+// terbitkanJumlahBelumDibaca mengirim jumlah notifikasi belum dibaca terbaru ke stream
+// setiap user.
+func terbitkanJumlahBelumDibaca(db *gorm.DB, userIDs ...uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	var rows []struct {
+		UserID uint
+		Jumlah int64
+	}
+	if err := db.Model(&models.Notification{}).
+		Select("user_id, COUNT(*) AS jumlah").
+		Where("user_id IN ? AND is_read = ? AND archived_at IS NULL", userIDs, false).
+		Group("user_id").
+		Scan(&rows).Error; err != nil {
+		log.Printf("Failed to count unread notifications for stream: %v", err)
+		return
+	}
+	jumlah := make(map[uint]int64, len(userIDs))
+	for _, row := range rows {
+		jumlah[row.UserID] = row.Jumlah
+	}
+
+	hub := helper.GetRealtimeHub()
+	for _, userID := range userIDs {
+		hub.Publish(topikUser(userID), helper.RealtimeEvent{
+			Type: EventJumlahBelumDibaca,
+			Data: fiber.Map{"unread_count": jumlah[userID]},
+		})
+	}
+}
+
+// terbitkanLaporanAdmin memberi tahu dashboard admin tentang laporan baru atau
+// perubahan status. Isi laporan tidak ikut dikirim; dashboard mengambil detail sendiri.
+func terbitkanLaporanAdmin(tipe string, laporan models.Laporan) {
+	helper.GetRealtimeHub().Publish(topikAdmin, helper.RealtimeEvent{
+		Type: tipe,
+		Data: fiber.Map{
+			"no_registrasi":         laporan.NoRegistrasi,
+			"status":                laporan.Status,
+			"kategori_kekerasan_id": laporan.KategoriKekerasanID,
+			"updated_at":            laporan.UpdatedAt,
+		},
+	})
+}
+
+// kunciTokenStream sengaja berbeda dari JWT_SECRET_KEY agar token stream tidak
+// diterima middleware sebagai token login di endpoint lain.
+func kunciTokenStream() []byte {
+	return []byte(os.Getenv("JWT_SECRET_KEY") + ":stream")
+}
+
+// waktuBerakhir membaca klaim exp (detik Unix) dari JWT.
+func waktuBerakhir(claims jwt.MapClaims, key string) (time.Time, bool) {
+	exp, ok := claims[key].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// BuatTokenStream menerbitkan token singkat untuk membuka stream notifikasi dari
+// klien yang tidak bisa mengirim header Authorization, misalnya EventSource di
+// browser: GET /api/stream-notifikasi?token=<token>.
+func BuatTokenStream(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	userToken, _ := c.Locals("user").(*jwt.Token)
+	var sesiBerakhir time.Time
+	if ok && userToken != nil {
+		sesiBerakhir, ok = waktuBerakhir(userToken.Claims.(jwt.MapClaims), "exp")
+	}
+	if !ok {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		return c.Status(http.StatusUnauthorized).JSON(response)
+	}
+
+	berakhir := time.Now().Add(streamTokenTTL)
+	if sesiBerakhir.Before(berakhir) {
+		berakhir = sesiBerakhir
+	}
+	claims := jwt.MapClaims{
+		"user_id":  userID,
+		"role":     role,
+		"exp":      berakhir.Unix(),
+		"sesi_exp": sesiBerakhir.Unix(),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(kunciTokenStream())
+	if err != nil {
+		log.Printf("Failed to sign stream token: %v", err)
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to create stream token",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Stream token created",
+		Data: fiber.Map{
+			"token":      signed,
+			"expires_at": berakhir,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// StreamNotifikasi membuka Server-Sent Events untuk klien yang bisa mengirim header
+// Authorization (aplikasi mobile, fetch dengan ReadableStream).
+func StreamNotifikasi(c *fiber.Ctx) error {
+	userID, role, _ := currentUserClaims(c)
+	var berakhir time.Time
+	if userToken, ok := c.Locals("user").(*jwt.Token); ok {
+		berakhir, _ = waktuBerakhir(userToken.Claims.(jwt.MapClaims), "exp")
+	}
+	return bukaStream(c, userID, role, berakhir)
+}
+
+// StreamNotifikasiDenganToken membuka stream dengan token dari BuatTokenStream pada
+// query ?token=, untuk EventSource di browser. Stream tetap ditutup saat JWT login
+// yang menerbitkan token tersebut habis.
+func StreamNotifikasiDenganToken(c *fiber.Ctx) error {
+	token, err := jwt.Parse(c.Query("token"), func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return kunciTokenStream(), nil
+	})
+	var claims jwt.MapClaims
+	if err == nil && token.Valid {
+		claims, _ = token.Claims.(jwt.MapClaims)
+	}
+	userID, okUser := claims["user_id"].(float64)
+	role, _ := claims["role"].(string)
+	berakhir, okSesi := waktuBerakhir(claims, "sesi_exp")
+	if claims == nil || !okUser || !okSesi {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized: Invalid stream token",
+		}
+		return c.Status(http.StatusUnauthorized).JSON(response)
+	}
+	return bukaStream(c, uint(userID), role, berakhir)
+}
+
+// bukaStream mengirim notifikasi baru dan jumlah belum dibaca, ditambah event laporan
+// untuk admin, sampai klien menutup koneksi atau sesi login berakhir.
+func bukaStream(c *fiber.Ctx, userID uint, role string, berakhir time.Time) error {
+	topics := []string{topikUser(userID)}
+	if role == "admin" {
+		topics = append(topics, topikAdmin)
+	}
+
+	var belumDibaca int64
+	if err := database.GetGormDBInstance().Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND archived_at IS NULL", userID, false).
+		Count(&belumDibaca).Error; err != nil {
+		log.Printf("Failed to count unread notifications: %v", err)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	events, unsubscribe := helper.GetRealtimeHub().Subscribe(topics...)
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+		var sesiHabis <-chan time.Time
+		if !berakhir.IsZero() {
+			timer := time.NewTimer(time.Until(berakhir))
+			defer timer.Stop()
+			sesiHabis = timer.C
+		}
+
+		awal := helper.RealtimeEvent{Type: EventJumlahBelumDibaca, Data: fiber.Map{"unread_count": belumDibaca}}
+		if err := tulisEventSSE(w, awal); err != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := tulisEventSSE(w, event); err != nil {
+					return
+				}
+			case <-sesiHabis:
+				tulisEventSSE(w, helper.RealtimeEvent{Type: EventSesiBerakhir, Data: fiber.Map{}})
+				return
+			case <-ticker.C:
+				// Komentar SSE; gagal flush berarti klien sudah menutup koneksi
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	return nil
+}
+
+func tulisEventSSE(w *bufio.Writer, event helper.RealtimeEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		log.Printf("Failed to encode realtime event %s: %v", event.Type, err)
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentUserClaims mengambil user_id dan role dari token yang disimpan middleware.
+func currentUserClaims(c *fiber.Ctx) (uint, string, bool) {
+	userToken, ok := c.Locals("user").(*jwt.Token)
+	if !ok || userToken == nil {
+		return 0, "", false
+	}
+	claims, ok := userToken.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "", false
+	}
+	userIDFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "", false
+	}
+	role, _ := claims["role"].(string)
+	return uint(userIDFloat), role, true
+}
+
+// sanitizeResponseData menyamarkan field sensitif sesuai role pemanggil. Nilai asli
+// hanya dibuka jika role diizinkan dan query ?reveal=true dikirim; setiap reveal
+// dicatat ke tabel audit. Jika audit gagal, data tetap dikembalikan dalam bentuk tersamar.
+func sanitizeResponseData(c *fiber.Ctx, resource, resourceID string, data any) (any, error) {
+	userID, role, _ := currentUserClaims(c)
+	reveal := c.QueryBool("reveal")
+
+	sanitized, revealed, err := helper.SanitizeForRole(data, role, reveal)
+	if err != nil {
+		return nil, err
+	}
+	if len(revealed) == 0 {
+		return sanitized, nil
+	}
+
+	access := models.SensitiveDataAccess{
+		UserID:     userID,
+		Role:       role,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Fields:     strings.Join(revealed, ","),
+		IPAddress:  c.IP(),
+		CreatedAt:  time.Now(),
+	}
+	if err := database.GetGormDBInstance().Create(&access).Error; err != nil {
+		log.Printf("Failed to audit sensitive data access, returning masked data: %v", err)
+		masked, _, err := helper.SanitizeForRole(data, role, false)
+		return masked, err
+	}
+	return sanitized, nil
+}
+
+// ownResponseData dipakai untuk data milik pemanggil sendiri: hanya field rahasia yang dibuang.
+func ownResponseData(data any) any {
+	sanitized, err := helper.SanitizeOwn(data)
+	if err != nil {
+		log.Printf("Failed to sanitize response data: %v", err)
+		return nil
+	}
+	return sanitized
+}
+
+func GetSensitiveDataAccessLogs(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	db := database.GetGormDBInstance()
+	query := db.Model(&models.SensitiveDataAccess{})
+	if resource := c.Query("resource"); resource != "" {
+		query = query.Where("resource = ?", resource)
+	}
+	if resourceID := c.Query("resource_id"); resourceID != "" {
+		query = query.Where("resource_id = ?", resourceID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to count access logs",
+		})
+	}
+
+	var logs []models.SensitiveDataAccess
+	if err := query.Order("created_at DESC").Limit(limit).Offset((page - 1) * limit).Find(&logs).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve access logs",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Sensitive data access logs retrieved successfully",
+		Data: fiber.Map{
+			"logs": logs,
+			"pagination": fiber.Map{
+				"total":       total,
+				"page":        page,
+				"limit":       limit,
+				"total_pages": (total + int64(limit) - 1) / int64(limit),
+			},
+		},
+	})
+}
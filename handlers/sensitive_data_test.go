@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/middleware"
+	"backend-pedika-fiber/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+func siapkanJanjiTemuSensitifTest(t *testing.T) *fiber.App {
+	t.Helper()
+	siapkanDBTest(t, &models.JanjiTemu{}, &models.UsulanJadwalJanjiTemu{}, &models.SensitiveDataAccess{})
+	buatUserLengkapTest(t, models.User{ID: 1, FullName: "Warga", Username: "warga", Role: "masyarakat",
+		PhoneNumber: "081234567890", Email: "warga@example.com", NIK: 1234567890123456, Alamat: "Jalan Mawar No. 1", Password: "hash"})
+	buatUserLengkapTest(t, models.User{ID: 2, FullName: "Petugas", Username: "petugas", Role: "admin",
+		PhoneNumber: "089876543210", Email: "petugas@example.com", Alamat: "Jalan Melati No. 2", Password: "hash"})
+	buatUserLengkapTest(t, models.User{ID: 3, FullName: "Warga Lain", Username: "lain", Role: "masyarakat",
+		PhoneNumber: "085500000000", Email: "lain@example.com"})
+
+	petugas := uint(2)
+	mulai := time.Now().Add(24 * time.Hour)
+	janjiTemu := models.JanjiTemu{ID: 10, UserID: 1, WaktuDimulai: mulai, WaktuSelesai: mulai.Add(time.Hour),
+		KeperluanKonsultasi: "Konsultasi", Status: "Disetujui", UserIDTolakSetujui: &petugas}
+	if err := database.DB.Omit(clause.Associations).Create(&janjiTemu).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/masyarakat/janjitemus", middleware.MasyarakatMiddleware, GetUserJanjiTemus)
+	app.Get("/masyarakat/detail-janjitemu/:id", middleware.MasyarakatMiddleware, GetJanjiTemuByID)
+	app.Get("/admin/detail-janjitemu/:id", middleware.AdminMiddleware, AdminJanjiTemuByID)
+	return app
+}
+
+func jumlahAuditTest(t *testing.T) int64 {
+	t.Helper()
+	var total int64
+	if err := database.DB.Model(&models.SensitiveDataAccess{}).Count(&total).Error; err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestJanjiTemuMilikSendiriTidakDisamarkan(t *testing.T) {
+	app := siapkanJanjiTemuSensitifTest(t)
+
+	status, body := requestTest(t, app, "GET", "/masyarakat/janjitemus", tokenTest(t, 1, "masyarakat"), "")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", status, body)
+	}
+	list, _ := body["Data"].([]any)
+	if len(list) != 1 {
+		t.Fatalf("expected 1 janji temu, got %v", body["Data"])
+	}
+	janjiTemu := list[0].(map[string]any)
+	pemohon := janjiTemu["user"].(map[string]any)
+	if pemohon["phone_number"] != "081234567890" || pemohon["alamat"] != "Jalan Mawar No. 1" {
+		t.Errorf("own data is masked: phone=%v alamat=%v", pemohon["phone_number"], pemohon["alamat"])
+	}
+	if _, ada := pemohon["password"]; ada {
+		t.Error("password leaked in own data")
+	}
+	petugas := janjiTemu["user_tolak_setujui"].(map[string]any)
+	if petugas["phone_number"] != "*********210" || petugas["alamat"] != "Jalan ***" {
+		t.Errorf("staff data not masked: phone=%v alamat=%v", petugas["phone_number"], petugas["alamat"])
+	}
+
+	status, body = requestTest(t, app, "GET", "/masyarakat/detail-janjitemu/10", tokenTest(t, 1, "masyarakat"), "")
+	if status != http.StatusOK {
+		t.Fatalf("detail status = %d, want 200: %v", status, body)
+	}
+	detail := body["Data"].(map[string]any)
+	if petugas := detail["user_tolak_setujui"].(map[string]any); petugas["phone_number"] != "*********210" {
+		t.Errorf("staff phone in detail = %v, want masked", petugas["phone_number"])
+	}
+	if total := jumlahAuditTest(t); total != 0 {
+		t.Errorf("owner views must not be audited, got %d rows", total)
+	}
+}
+
+func TestJanjiTemuMilikOrangLainDitolak(t *testing.T) {
+	app := siapkanJanjiTemuSensitifTest(t)
+
+	status, _ := requestTest(t, app, "GET", "/masyarakat/detail-janjitemu/10", tokenTest(t, 3, "masyarakat"), "")
+	if status != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", status)
+	}
+}
+
+func TestRevealDataSensitifDiaudit(t *testing.T) {
+	app := siapkanJanjiTemuSensitifTest(t)
+	admin := tokenTest(t, 2, "admin")
+
+	status, body := requestTest(t, app, "GET", "/admin/detail-janjitemu/10", admin, "")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", status, body)
+	}
+	pemohon := body["Data"].(map[string]any)["user"].(map[string]any)
+	if pemohon["phone_number"] != "*********890" || pemohon["nik"] != "************3456" {
+		t.Errorf("staff view without reveal not masked: phone=%v nik=%v", pemohon["phone_number"], pemohon["nik"])
+	}
+	if total := jumlahAuditTest(t); total != 0 {
+		t.Fatalf("masked view must not be audited, got %d rows", total)
+	}
+
+	status, body = requestTest(t, app, "GET", "/admin/detail-janjitemu/10?reveal=true", admin, "")
+	if status != http.StatusOK {
+		t.Fatalf("reveal status = %d, want 200: %v", status, body)
+	}
+	pemohon = body["Data"].(map[string]any)["user"].(map[string]any)
+	if pemohon["phone_number"] != "081234567890" {
+		t.Errorf("revealed phone = %v, want original", pemohon["phone_number"])
+	}
+	var access models.SensitiveDataAccess
+	if err := database.DB.First(&access).Error; err != nil {
+		t.Fatalf("reveal not audited: %v", err)
+	}
+	if access.UserID != 2 || access.Role != "admin" || access.Resource != "janji_temu" || access.ResourceID != "10" {
+		t.Errorf("unexpected audit row: %+v", access)
+	}
+	if access.Fields != "alamat,nik,phone_number" {
+		t.Errorf("audited fields = %q, want alamat,nik,phone_number", access.Fields)
+	}
+}
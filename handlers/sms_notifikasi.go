@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+var errTanpaNomorTelepon = errors.New("user has no phone number")
+
+// saluranCadangan membaca NOTIFICATION_FALLBACK_CHANNEL ("sms" atau "whatsapp").
+func saluranCadangan() string {
+	if os.Getenv("NOTIFICATION_FALLBACK_CHANNEL") == SaluranWhatsApp {
+		return SaluranWhatsApp
+	}
+	return SaluranSMS
+}
+
+// antrekanCadangan membuat baris outbox SMS/WhatsApp untuk notifikasi push yang
+// tidak punya perangkat tujuan, jika user mengaktifkan saluran SMS (opt-in) dan
+// penyedia SMS sudah dikonfigurasi.
+func antrekanCadangan(item models.NotificationOutbox, pref models.PreferensiNotifikasi, data models.FCMNotificationData, now time.Time) (bool, error) {
+	if _, err := helper.GetMessagingProvider(); err != nil {
+		return false, nil
+	}
+	pengaturan, err := pengaturanDari(pref)
+	if err != nil {
+		return false, err
+	}
+	if !pengaturan.aktif(kategoriPreferensi(data), SaluranSMS) {
+		return false, nil
+	}
+	cadangan := models.NotificationOutbox{
+		NotificationID: item.NotificationID,
+		UserID:         item.UserID,
+		Saluran:        saluranCadangan(),
+		Title:          item.Title,
+		Body:           item.Body,
+		Payload:        item.Payload,
+		Status:         OutboxStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := database.GetGormDBInstance().Create(&cadangan).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func teksPesanSingkat(item models.NotificationOutbox, pref models.PreferensiNotifikasi) string {
+	if pref.ModeSamaran {
+		return "Pelita Pena: Ada pemberitahuan baru. Buka aplikasi untuk melihat."
+	}
+	return "Pelita Pena - " + item.Title + ": " + item.Body
+}
+
+// prosesOutboxPesanSingkat mengirim baris outbox SMS/WhatsApp lewat penyedia aktif.
+func prosesOutboxPesanSingkat(batch []models.NotificationOutbox, prefs map[uint]models.PreferensiNotifikasi) {
+	userIDs := make([]uint, 0, len(batch))
+	for _, item := range batch {
+		userIDs = append(userIDs, item.UserID)
+	}
+	var users []models.User
+	if err := database.GetGormDBInstance().Select("id", "phone_number").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		for _, item := range batch {
+			catatHasilOutbox(item, err)
+		}
+		return
+	}
+	nomor := make(map[uint]string, len(users))
+	for _, user := range users {
+		nomor[user.ID] = user.PhoneNumber
+	}
+
+	provider, err := helper.GetMessagingProvider()
+	if err != nil {
+		for _, item := range batch {
+			catatHasilOutbox(item, err)
+		}
+		return
+	}
+	for _, item := range batch {
+		if nomor[item.UserID] == "" {
+			catatHasilOutbox(item, errTanpaNomorTelepon)
+			continue
+		}
+		err := provider.Send(context.Background(), helper.TextMessage{
+			Channel: item.Saluran,
+			To:      nomor[item.UserID],
+			Body:    teksPesanSingkat(item, prefs[item.UserID]),
+		})
+		catatHasilOutbox(item, err)
+	}
+}
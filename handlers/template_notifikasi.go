@@ -0,0 +1,412 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	BahasaIndonesia = "id"
+	BahasaInggris   = "en"
+)
+
+var bahasaDidukung = []string{BahasaIndonesia, BahasaInggris}
+
+// Variabel template per jenis kejadian. Admin hanya bisa memakai field yang ada di
+// struct ini; template yang memakai field lain ditolak saat disimpan.
+type varsLaporan struct {
+	NoRegistrasi string
+}
+
+type varsTracking struct {
+	NoRegistrasi string
+	AdaDokumen   bool
+}
+
+type varsJanjiTemu struct {
+	WaktuDimulai time.Time
+	WaktuSelesai time.Time
+	Alasan       string
+	SisaWaktu    time.Duration
+}
+
+type varsLaporanAdmin struct {
+	ReportID string
+}
+
+type varsLaporanDibatalkan struct {
+	NoRegistrasi string
+	Alasan       string
+}
+
+type varsKosong struct{}
+
+type teksTemplate struct {
+	Judul string
+	Isi   string
+}
+
+type templateBawaan struct {
+	Vars any
+	Teks map[string]teksTemplate
+}
+
+// templateNotifikasiBawaan adalah registry template per kejadian dan bahasa.
+var templateNotifikasiBawaan = map[string]templateBawaan{
+	"laporan_diproses": {Vars: varsLaporan{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Status Laporan Diperbarui", "Laporan Anda dengan ID {{.NoRegistrasi}} sedang diproses"},
+		BahasaInggris:   {"Report Status Updated", "Your report {{.NoRegistrasi}} is being processed"},
+	}},
+	"laporan_selesai": {Vars: varsLaporan{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Laporan Selesai", "Laporan Anda dengan ID {{.NoRegistrasi}} telah selesai"},
+		BahasaInggris:   {"Report Completed", "Your report {{.NoRegistrasi}} has been completed"},
+	}},
+	"tracking_dibuat": {Vars: varsTracking{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Update Baru pada Laporanmu!", "Halo! Tracking laporan dengan No. {{.NoRegistrasi}} telah ditambahkan. {{if .AdaDokumen}}Ada dokumen baru (PDF/Image) yang diunggah untuk laporanmu! 📎{{else}}Ada update baru nih!{{end}} Cek sekarang yuk!"},
+		BahasaInggris:   {"New Update on Your Report!", "A tracking update was added to report {{.NoRegistrasi}}. {{if .AdaDokumen}}New documents (PDF/Image) were uploaded for your report! 📎{{else}}There is something new!{{end}} Check it now!"},
+	}},
+	"tracking_diperbarui": {Vars: varsTracking{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Tracking Laporanmu Diperbarui!", "Yay! Tracking untuk laporan No. {{.NoRegistrasi}} telah diperbarui. {{if .AdaDokumen}}Dokumen baru (PDF/Image) telah diperbarui untuk laporanmu!{{else}}Ada perubahan terbaru pada tracking laporanmu!{{end}} Yuk cek detailnya!"},
+		BahasaInggris:   {"Your Report Tracking Was Updated!", "Tracking for report {{.NoRegistrasi}} was updated. {{if .AdaDokumen}}New documents (PDF/Image) were added to your report!{{else}}There are new changes on your report tracking!{{end}} Check the details!"},
+	}},
+	"tracking_dihapus": {Vars: varsTracking{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Tracking Laporan telah dihapus!", "Halo! Tracking untuk laporan No. {{.NoRegistrasi}} telah dihapus dari sistem. Ada pertanyaan? Hubungi kami ya!"},
+		BahasaInggris:   {"Report Tracking Removed", "A tracking entry for report {{.NoRegistrasi}} was removed. Questions? Contact us!"},
+	}},
+	"janji_temu_disetujui": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Yay! Janji Pertemuan Kamu Telah Disetujui!", "Hore! Jadwal janji temu kamu pada {{tanggal .WaktuDimulai}} telah disetujui. Jangan lupakan janji kita ya!"},
+		BahasaInggris:   {"Your Appointment Was Approved!", "Your appointment on {{tanggal .WaktuDimulai}} has been approved. See you there!"},
+	}},
+	"janji_temu_ditolak": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Oops! Janji Pertemuan Kamu Ditolak..", "Sayang sekali, janji temu kamu pada {{tanggal .WaktuDimulai}} ditolak. Alasan: {{.Alasan}}"},
+		BahasaInggris:   {"Your Appointment Was Declined", "Unfortunately your appointment on {{tanggal .WaktuDimulai}} was declined. Reason: {{.Alasan}}"},
+	}},
+	"janji_temu_pengingat": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Pengingat Janji Temu", "Janji temu pada {{tanggal .WaktuDimulai}} akan dimulai dalam {{durasi .SisaWaktu}}. Sampai jumpa!"},
+		BahasaInggris:   {"Appointment Reminder", "Your appointment on {{tanggal .WaktuDimulai}} starts in {{durasi .SisaWaktu}}. See you soon!"},
+	}},
+	"usulan_jadwal_diajukan": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Usulan Jadwal Baru Janji Temu", "Ada usulan jadwal baru untuk janji temu kamu: {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}. Alasan: {{.Alasan}}. Silakan terima atau tolak usulan ini."},
+		BahasaInggris:   {"New Appointment Time Proposed", "A new time was proposed for your appointment: {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}. Reason: {{.Alasan}}. Please accept or decline it."},
+	}},
+	"usulan_jadwal_diajukan_aktor": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Usulan Jadwal Baru Janji Temu", "Usulan jadwal {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}} sudah dikirim dan menunggu tanggapan."},
+		BahasaInggris:   {"New Appointment Time Proposed", "Your proposed time {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}} was sent and is awaiting a response."},
+	}},
+	"usulan_jadwal_diterima": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Jadwal Baru Janji Temu Disetujui", "Usulan jadwal kamu diterima. Janji temu sekarang dijadwalkan pada {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+		BahasaInggris:   {"New Appointment Time Accepted", "Your proposed time was accepted. The appointment is now on {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+	}},
+	"usulan_jadwal_diterima_aktor": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Jadwal Baru Janji Temu Disetujui", "Kamu menerima usulan jadwal. Janji temu sekarang dijadwalkan pada {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+		BahasaInggris:   {"New Appointment Time Accepted", "You accepted the proposed time. The appointment is now on {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+	}},
+	"usulan_jadwal_ditolak": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Usulan Jadwal Janji Temu Ditolak", "Usulan jadwal baru ditolak, janji temu tetap pada {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}.{{if .Alasan}} Alasan: {{.Alasan}}{{end}}"},
+		BahasaInggris:   {"Appointment Time Proposal Declined", "The proposed time was declined, the appointment stays on {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}.{{if .Alasan}} Reason: {{.Alasan}}{{end}}"},
+	}},
+	"usulan_jadwal_ditolak_aktor": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Usulan Jadwal Janji Temu Ditolak", "Kamu menolak usulan jadwal. Janji temu tetap pada {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+		BahasaInggris:   {"Appointment Time Proposal Declined", "You declined the proposed time. The appointment stays on {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}}."},
+	}},
+	"chat_peringatan_klien": {Vars: varsKosong{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Peringatan: Pelanggaran Etika Komunikasi", "Anda telah dilaporkan karena menggunakan kata-kata tidak pantas dalam chat."},
+		BahasaInggris:   {"Warning: Communication Policy Violation", "You have been reported for using inappropriate language in chat."},
+	}},
+	"chat_laporan_admin_diterima": {Vars: varsLaporanAdmin{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Terima Kasih atas Laporan Anda", "Terima kasih telah melaporkan admin yang menggunakan kata-kata tidak pantas. Laporan Anda dengan ID {{.ReportID}} telah diterima dan sedang diverifikasi oleh tim kami."},
+		BahasaInggris:   {"Thank You for Your Report", "Thank you for reporting an admin who used inappropriate language. Your report {{.ReportID}} was received and is being reviewed by our team."},
+	}},
+	"admin_laporan_baru": {Vars: varsLaporan{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Laporan Baru Masuk", "Laporan baru dengan No. {{.NoRegistrasi}} baru saja masuk dan menunggu ditinjau."},
+		BahasaInggris:   {"New Report Submitted", "A new report {{.NoRegistrasi}} was just submitted and is awaiting review."},
+	}},
+	"admin_laporan_dibatalkan": {Vars: varsLaporanDibatalkan{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Laporan Dibatalkan", "Laporan No. {{.NoRegistrasi}} dibatalkan oleh pelapor. Alasan: {{.Alasan}}"},
+		BahasaInggris:   {"Report Cancelled", "Report {{.NoRegistrasi}} was cancelled by the reporter. Reason: {{.Alasan}}"},
+	}},
+	"admin_janji_temu_baru": {Vars: varsJanjiTemu{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Permintaan Janji Temu Baru", "Ada permintaan janji temu baru pada {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}} yang menunggu persetujuan."},
+		BahasaInggris:   {"New Appointment Request", "A new appointment was requested for {{tanggal .WaktuDimulai}} - {{jam .WaktuSelesai}} and is awaiting approval."},
+	}},
+	"admin_keluhan_chat": {Vars: varsLaporanAdmin{}, Teks: map[string]teksTemplate{
+		BahasaIndonesia: {"Keluhan Baru dari Pengguna", "Seorang pengguna melaporkan admin karena kata-kata tidak pantas di chat. ID laporan: {{.ReportID}}."},
+		BahasaInggris:   {"New Complaint from a User", "A user reported an admin for inappropriate language in chat. Report ID: {{.ReportID}}."},
+	}},
+}
+
+func fungsiTemplate(bahasa string) template.FuncMap {
+	return template.FuncMap{
+		"tanggal": func(t time.Time) string { return t.Format("02-01-2006 15:04") },
+		"jam":     func(t time.Time) string { return t.Format("15:04") },
+		"durasi": func(d time.Duration) string {
+			if bahasa == BahasaInggris {
+				switch {
+				case d >= 24*time.Hour:
+					return fmt.Sprintf("%d day(s)", int(d.Hours()/24))
+				case d >= time.Hour:
+					return fmt.Sprintf("%d hour(s)", int(d.Hours()))
+				}
+				return fmt.Sprintf("%d minute(s)", int(d.Minutes()))
+			}
+			return formatSisaWaktu(d)
+		},
+	}
+}
+
+func eksekusiTemplate(bahasa, teks string, vars any) (string, error) {
+	tmpl, err := template.New("notifikasi").Funcs(fungsiTemplate(bahasa)).Option("missingkey=error").Parse(teks)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderTeks(bahasa string, teks teksTemplate, vars any) (string, string, error) {
+	judul, err := eksekusiTemplate(bahasa, teks.Judul, vars)
+	if err != nil {
+		return "", "", err
+	}
+	isi, err := eksekusiTemplate(bahasa, teks.Isi, vars)
+	if err != nil {
+		return "", "", err
+	}
+	return judul, isi, nil
+}
+
+func bawaanUntuk(bawaan templateBawaan, bahasa string) teksTemplate {
+	if teks, ok := bawaan.Teks[bahasa]; ok {
+		return teks
+	}
+	return bawaan.Teks[BahasaIndonesia]
+}
+
+// renderNotifikasi menghasilkan judul dan isi notifikasi dalam bahasa pilihan user.
+// Template yang diubah admin dipakai lebih dulu; jika gagal dirender, template
+// bawaan dipakai agar notifikasi tetap terkirim.
+func renderNotifikasi(db *gorm.DB, kunci string, userID uint, vars any) (string, string, error) {
+	bawaan, ok := templateNotifikasiBawaan[kunci]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification template %q", kunci)
+	}
+	bahasa := BahasaIndonesia
+	if pref, _, err := muatPreferensi(db, userID); err == nil && pref.Bahasa != "" {
+		bahasa = pref.Bahasa
+	}
+
+	var custom models.TemplateNotifikasi
+	if err := db.Where("kunci = ? AND bahasa = ?", kunci, bahasa).First(&custom).Error; err == nil {
+		judul, isi, err := renderTeks(bahasa, teksTemplate{Judul: custom.Judul, Isi: custom.Isi}, vars)
+		if err == nil {
+			return judul, isi, nil
+		}
+		log.Printf("Failed to render custom notification template %s/%s: %v", kunci, bahasa, err)
+	}
+	return renderTeks(bahasa, bawaanUntuk(bawaan, bahasa), vars)
+}
+
+// antrekanNotifikasiTemplate sama dengan antrekanNotifikasi, tetapi judul dan isi
+// diambil dari template kunci.
+func antrekanNotifikasiTemplate(tx *gorm.DB, userID uint, kunci string, vars any, data models.FCMNotificationData, now time.Time) (*models.Notification, error) {
+	judul, isi, err := renderNotifikasi(tx, kunci, userID, vars)
+	if err != nil {
+		return nil, err
+	}
+	return antrekanNotifikasi(tx, userID, judul, isi, data, now)
+}
+
+func kirimNotifikasiTemplate(db *gorm.DB, userID uint, kunci string, vars any, data models.FCMNotificationData, now time.Time) error {
+	judul, isi, err := renderNotifikasi(db, kunci, userID, vars)
+	if err != nil {
+		return err
+	}
+	return kirimNotifikasi(db, userID, judul, isi, data, now)
+}
+
+// variabelTemplate mendaftar nama dan tipe variabel yang boleh dipakai template.
+func variabelTemplate(vars any) map[string]string {
+	hasil := map[string]string{}
+	t := reflect.TypeOf(vars)
+	for i := 0; i < t.NumField(); i++ {
+		hasil[t.Field(i).Name] = t.Field(i).Type.String()
+	}
+	return hasil
+}
+
+/*=========================== ADMIN: TEMPLATE NOTIFIKASI =======================*/
+
+type templateNotifikasiResponse struct {
+	Kunci    string            `json:"kunci"`
+	Bahasa   string            `json:"bahasa"`
+	Judul    string            `json:"judul"`
+	Isi      string            `json:"isi"`
+	Diubah   bool              `json:"diubah"`
+	Variabel map[string]string `json:"variabel"`
+}
+
+func AdminGetTemplateNotifikasi(c *fiber.Ctx) error {
+	var custom []models.TemplateNotifikasi
+	if err := database.DB.Find(&custom).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mengambil template notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	diubah := map[string]models.TemplateNotifikasi{}
+	for _, t := range custom {
+		diubah[t.Kunci+"/"+t.Bahasa] = t
+	}
+
+	kunciList := make([]string, 0, len(templateNotifikasiBawaan))
+	for kunci := range templateNotifikasiBawaan {
+		kunciList = append(kunciList, kunci)
+	}
+	sort.Strings(kunciList)
+
+	daftar := []templateNotifikasiResponse{}
+	for _, kunci := range kunciList {
+		bawaan := templateNotifikasiBawaan[kunci]
+		for _, bahasa := range bahasaDidukung {
+			item := templateNotifikasiResponse{Kunci: kunci, Bahasa: bahasa, Variabel: variabelTemplate(bawaan.Vars)}
+			if t, ok := diubah[kunci+"/"+bahasa]; ok {
+				item.Judul, item.Isi, item.Diubah = t.Judul, t.Isi, true
+			} else {
+				teks := bawaanUntuk(bawaan, bahasa)
+				item.Judul, item.Isi = teks.Judul, teks.Isi
+			}
+			daftar = append(daftar, item)
+		}
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Daftar template notifikasi",
+		Data:    daftar,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+func validasiKunciBahasa(kunci, bahasa string) (templateBawaan, error) {
+	bawaan, ok := templateNotifikasiBawaan[kunci]
+	if !ok {
+		return bawaan, errors.New("Template notifikasi tidak ditemukan")
+	}
+	if !berisi(bahasaDidukung, bahasa) {
+		return bawaan, errors.New("Bahasa tidak didukung")
+	}
+	return bawaan, nil
+}
+
+// AdminSimpanTemplateNotifikasi mengganti template untuk satu kunci dan bahasa.
+// Template dicoba dirender dengan variabel kosong agar kesalahan sintaks atau
+// nama variabel ketahuan sebelum disimpan.
+func AdminSimpanTemplateNotifikasi(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	kunci, bahasa := c.Params("kunci"), c.Params("bahasa")
+	bawaan, err := validasiKunciBahasa(kunci, bahasa)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: err.Error(),
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+
+	judul := strings.TrimSpace(c.FormValue("judul"))
+	isi := strings.TrimSpace(c.FormValue("isi"))
+	if judul == "" || isi == "" {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Judul dan isi wajib diisi",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+	if _, _, err := renderTeks(bahasa, teksTemplate{Judul: judul, Isi: isi}, bawaan.Vars); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Template tidak valid: " + err.Error(),
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	now := time.Now()
+	templateBaru := models.TemplateNotifikasi{
+		Kunci:       kunci,
+		Bahasa:      bahasa,
+		Judul:       judul,
+		Isi:         isi,
+		UpdatedByID: userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kunci"}, {Name: "bahasa"}},
+		DoUpdates: clause.AssignmentColumns([]string{"judul", "isi", "updated_by_id", "updated_at"}),
+	}).Create(&templateBaru).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal menyimpan template notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Template notifikasi berhasil disimpan",
+		Data:    templateBaru,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// AdminResetTemplateNotifikasi menghapus perubahan admin sehingga template bawaan
+// dipakai kembali.
+func AdminResetTemplateNotifikasi(c *fiber.Ctx) error {
+	kunci, bahasa := c.Params("kunci"), c.Params("bahasa")
+	if _, err := validasiKunciBahasa(kunci, bahasa); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: err.Error(),
+		}
+		return c.Status(http.StatusNotFound).JSON(response)
+	}
+	if err := database.DB.Where("kunci = ? AND bahasa = ?", kunci, bahasa).Delete(&models.TemplateNotifikasi{}).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Gagal mereset template notifikasi",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Template notifikasi dikembalikan ke bawaan",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
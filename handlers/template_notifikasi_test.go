@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/middleware"
+	"backend-pedika-fiber/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func siapkanTemplateTest(t *testing.T) {
+	t.Helper()
+	siapkanDBTest(t, &models.TemplateNotifikasi{}, &models.PreferensiNotifikasi{})
+	// User 2 memilih bahasa Inggris; user 1 tidak punya preferensi (default Indonesia)
+	pref := models.PreferensiNotifikasi{UserID: 2, ZonaWaktu: zonaWaktuDefault, Bahasa: BahasaInggris}
+	if err := database.DB.Create(&pref).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateBawaanBisaDirender(t *testing.T) {
+	for kunci, bawaan := range templateNotifikasiBawaan {
+		for _, bahasa := range bahasaDidukung {
+			if _, ok := bawaan.Teks[bahasa]; !ok {
+				t.Errorf("%s has no %s text", kunci, bahasa)
+				continue
+			}
+			if _, _, err := renderTeks(bahasa, bawaan.Teks[bahasa], bawaan.Vars); err != nil {
+				t.Errorf("%s/%s: %v", kunci, bahasa, err)
+			}
+		}
+	}
+}
+
+func TestRenderNotifikasiSesuaiBahasaUser(t *testing.T) {
+	siapkanTemplateTest(t)
+	vars := varsJanjiTemu{WaktuDimulai: time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC), SisaWaktu: 2 * time.Hour}
+
+	judul, isi, err := renderNotifikasi(database.DB, "janji_temu_pengingat", 1, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if judul != "Pengingat Janji Temu" || !strings.Contains(isi, "07-01-2030 09:00") || !strings.Contains(isi, "2 jam") {
+		t.Errorf("indonesian render = %q / %q", judul, isi)
+	}
+
+	judul, isi, err = renderNotifikasi(database.DB, "janji_temu_pengingat", 2, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if judul != "Appointment Reminder" || !strings.Contains(isi, "2 hour(s)") {
+		t.Errorf("english render = %q / %q", judul, isi)
+	}
+
+	if _, _, err := renderNotifikasi(database.DB, "tidak_ada", 1, vars); err == nil {
+		t.Error("unknown template key should fail")
+	}
+}
+
+func TestRenderNotifikasiMemakaiTemplateAdmin(t *testing.T) {
+	siapkanTemplateTest(t)
+	custom := models.TemplateNotifikasi{Kunci: "laporan_selesai", Bahasa: BahasaIndonesia, Judul: "Selesai", Isi: "Laporan {{.NoRegistrasi}} sudah ditutup"}
+	if err := database.DB.Create(&custom).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	judul, isi, err := renderNotifikasi(database.DB, "laporan_selesai", 1, varsLaporan{NoRegistrasi: "REG-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if judul != "Selesai" || isi != "Laporan REG-1 sudah ditutup" {
+		t.Errorf("custom render = %q / %q", judul, isi)
+	}
+
+	// Template admin hanya untuk bahasa Indonesia; user berbahasa Inggris tetap mendapat bawaan
+	if judul, _, _ := renderNotifikasi(database.DB, "laporan_selesai", 2, varsLaporan{NoRegistrasi: "REG-1"}); judul != "Report Completed" {
+		t.Errorf("english user got %q, want default english title", judul)
+	}
+}
+
+func TestRenderNotifikasiKembaliKeBawaanJikaTemplateRusak(t *testing.T) {
+	siapkanTemplateTest(t)
+	// Disimpan langsung (melewati validasi admin), misalnya setelah field vars diganti nama
+	rusak := models.TemplateNotifikasi{Kunci: "laporan_selesai", Bahasa: BahasaIndonesia, Judul: "Selesai", Isi: "Laporan {{.NomorLama}}"}
+	if err := database.DB.Create(&rusak).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	judul, isi, err := renderNotifikasi(database.DB, "laporan_selesai", 1, varsLaporan{NoRegistrasi: "REG-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if judul != "Laporan Selesai" || isi != "Laporan Anda dengan ID REG-1 telah selesai" {
+		t.Errorf("fallback render = %q / %q", judul, isi)
+	}
+}
+
+func TestAdminSimpanTemplateMenolakVariabelTidakDikenal(t *testing.T) {
+	siapkanTemplateTest(t)
+	app := fiber.New()
+	app.Put("/admin/template-notifikasi/:kunci/:bahasa", middleware.AdminMiddleware, AdminSimpanTemplateNotifikasi)
+	admin := tokenTest(t, 9, "admin")
+
+	status, _ := requestTest(t, app, "PUT", "/admin/template-notifikasi/laporan_selesai/id", admin, "judul=Selesai&isi=Laporan+{{.Rahasia}}")
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown variable status = %d, want 400", status)
+	}
+	status, _ = requestTest(t, app, "PUT", "/admin/template-notifikasi/laporan_selesai/fr", admin, "judul=Fini&isi=Termine")
+	if status != http.StatusNotFound {
+		t.Errorf("unsupported language status = %d, want 404", status)
+	}
+	status, body := requestTest(t, app, "PUT", "/admin/template-notifikasi/laporan_selesai/id", admin, "judul=Selesai&isi=Laporan+{{.NoRegistrasi}}+ditutup")
+	if status != http.StatusOK {
+		t.Fatalf("valid template status = %d, want 200: %v", status, body)
+	}
+	if _, isi, _ := renderNotifikasi(database.DB, "laporan_selesai", 1, varsLaporan{NoRegistrasi: "REG-2"}); isi != "Laporan REG-2 ditutup" {
+		t.Errorf("saved template render = %q", isi)
+	}
+}
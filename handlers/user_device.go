@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// daftarkanPerangkat menyimpan token perangkat untuk user. Token bersifat unik: jika
+// token yang sama sebelumnya milik user lain (ganti akun di HP yang sama), token
+// dipindahkan ke user ini.
+func daftarkanPerangkat(db *gorm.DB, userID uint, info models.DeviceInfo, now time.Time) error {
+	token := strings.TrimSpace(info.NotificationToken)
+	if token == "" {
+		return nil
+	}
+	device := models.UserDevice{
+		UserID:     userID,
+		Token:      token,
+		Platform:   strings.ToLower(strings.TrimSpace(info.Platform)),
+		AppVersion: strings.TrimSpace(info.AppVersion),
+		Active:     true,
+		LastSeenAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	// Daftar ulang juga menghidupkan kembali token yang pernah dinonaktifkan
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "app_version", "active", "last_seen_at",
+			"failure_count", "last_error", "last_failure_at", "deactivated_at", "updated_at"}),
+	}).Create(&device).Error
+}
+
+// perangkatAktif mengembalikan token perangkat aktif per user.
+func perangkatAktif(db *gorm.DB, userIDs []uint) (map[uint][]string, error) {
+	var devices []models.UserDevice
+	if err := db.Select("user_id", "token").
+		Where("user_id IN ? AND active = ?", userIDs, true).
+		Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	tokens := make(map[uint][]string)
+	for _, device := range devices {
+		tokens[device.UserID] = append(tokens[device.UserID], device.Token)
+	}
+	return tokens, nil
+}
+
+// catatHasilPerangkat mencatat hasil pengiriman ke satu token. Token yang ditolak
+// permanen oleh FCM dinonaktifkan agar tidak dikirimi lagi.
+func catatHasilPerangkat(db *gorm.DB, token string, sendErr error, now time.Time) {
+	query := db.Model(&models.UserDevice{}).Where("token = ?", token)
+	var err error
+	switch {
+	case sendErr == nil:
+		err = query.Where("failure_count > 0").Updates(map[string]interface{}{
+			"failure_count": 0,
+			"last_error":    "",
+			"updated_at":    now,
+		}).Error
+	case helper.IsInvalidFCMToken(sendErr):
+		log.Printf("Deactivating invalid FCM token for device: %v", sendErr)
+		err = query.Updates(map[string]interface{}{
+			"active":          false,
+			"failure_count":   gorm.Expr("failure_count + 1"),
+			"last_error":      sendErr.Error(),
+			"last_failure_at": now,
+			"deactivated_at":  now,
+			"updated_at":      now,
+		}).Error
+	default:
+		err = query.Updates(map[string]interface{}{
+			"failure_count":   gorm.Expr("failure_count + 1"),
+			"last_error":      sendErr.Error(),
+			"last_failure_at": now,
+			"updated_at":      now,
+		}).Error
+	}
+	if err != nil {
+		log.Printf("Failed to record device delivery result: %v", err)
+	}
+}
+
+// LogoutUser menghapus token perangkat yang dipakai agar perangkat tersebut tidak
+// lagi menerima push untuk user ini.
+func LogoutUser(c *fiber.Ctx) error {
+	userID, _, ok := currentUserClaims(c)
+	if !ok {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		}
+		return c.Status(http.StatusUnauthorized).JSON(response)
+	}
+
+	var info models.DeviceInfo
+	if err := c.BodyParser(&info); err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid request body",
+		}
+		return c.Status(http.StatusBadRequest).JSON(response)
+	}
+
+	if token := strings.TrimSpace(info.NotificationToken); token != "" {
+		db := database.GetGormDBInstance()
+		if err := db.Where("user_id = ? AND token = ?", userID, token).Delete(&models.UserDevice{}).Error; err != nil {
+			log.Printf("Failed to remove device for user %d: %v", userID, err)
+			response := helper.ResponseWithOutData{
+				Code:    http.StatusInternalServerError,
+				Status:  "error",
+				Message: "Failed to remove device",
+			}
+			return c.Status(http.StatusInternalServerError).JSON(response)
+		}
+	}
+
+	response := helper.ResponseWithOutData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Logout berhasil",
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// GetUserDevices menampilkan perangkat yang terdaftar untuk user yang login.
+func GetUserDevices(c *fiber.Ctx) error {
+	userID, _, _ := currentUserClaims(c)
+	var devices []models.UserDevice
+	if err := database.GetGormDBInstance().Where("user_id = ?", userID).Order("last_seen_at desc").Find(&devices).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve devices",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "List of devices",
+		Data:    devices,
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
+
+// userTanpaPerangkat adalah baris laporan user yang tidak bisa dihubungi lewat push.
+type userTanpaPerangkat struct {
+	UserID            uint       `json:"user_id"`
+	FullName          string     `json:"full_name"`
+	Email             string     `json:"email"`
+	PhoneNumber       string     `json:"phone_number"`
+	PerangkatNonaktif int        `json:"perangkat_nonaktif"`
+	TerakhirGagal     *time.Time `json:"terakhir_gagal"`
+}
+
+// AdminGetUserTanpaPerangkat menampilkan masyarakat yang tidak punya perangkat aktif
+// (belum pernah login di aplikasi atau semua tokennya tidak valid), agar petugas
+// bisa menghubungi lewat telepon atau email.
+func AdminGetUserTanpaPerangkat(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	db := database.GetGormDBInstance()
+	query := db.Table("users").
+		Where("users.role = ?", "masyarakat").
+		Where("NOT EXISTS (SELECT 1 FROM user_devices d WHERE d.user_id = users.id AND d.active = ?)", true)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to count users",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	var rows []userTanpaPerangkat
+	if err := query.
+		Select("users.id AS user_id, users.full_name, users.email, users.phone_number, " +
+			"(SELECT COUNT(*) FROM user_devices d WHERE d.user_id = users.id) AS perangkat_nonaktif, " +
+			"(SELECT MAX(d.last_failure_at) FROM user_devices d WHERE d.user_id = users.id) AS terakhir_gagal").
+		Order("terakhir_gagal IS NULL, terakhir_gagal desc, users.id desc").
+		Offset((page - 1) * limit).Limit(limit).
+		Scan(&rows).Error; err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve users",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+
+	data, err := sanitizeResponseData(c, "user_tanpa_perangkat", "", rows)
+	if err != nil {
+		response := helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to process data",
+		}
+		return c.Status(http.StatusInternalServerError).JSON(response)
+	}
+	response := helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Users without reachable device",
+		Data: fiber.Map{
+			"items": data,
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	}
+	return c.Status(http.StatusOK).JSON(response)
+}
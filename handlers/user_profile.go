@@ -40,7 +40,7 @@ func GetUserProfile(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "User profile retrieved successfully",
-		Data:    user,
+		Data:    ownResponseData(user),
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -222,7 +222,7 @@ func UpdateUserProfile(c *fiber.Ctx) error {
 		Code:    http.StatusOK,
 		Status:  "success",
 		Message: "Profil Anda berhasil diupdate",
-		Data:    existingUser,
+		Data:    ownResponseData(existingUser),
 	}
 	return c.Status(http.StatusOK).JSON(response)
 }
@@ -309,6 +309,20 @@ func UpdateNotificationTokenHandler(c *fiber.Ctx) error {
         })
     }
 
+    // Daftarkan juga ke tabel perangkat agar push dikirim ke semua perangkat user
+    device := models.DeviceInfo{
+        NotificationToken: notificationToken,
+        Platform:          c.Query("platform"),
+        AppVersion:        c.Query("app_version"),
+    }
+    if err := daftarkanPerangkat(database.GetGormDBInstance(), userID, device, time.Now()); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(Response{
+            Success: 0,
+            Message: err.Error(),
+            Data:    nil,
+        })
+    }
+
     // Update token di database
     if err := UpdateNotificationToken(userID, notificationToken); err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(Response{
@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/helper"
+	"backend-pedika-fiber/models"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Status janji temu selama ada usulan jadwal baru yang belum ditanggapi.
+const StatusJanjiTemuDiusulkanUlang = "Diusulkan ulang"
+
+const (
+	StatusUsulanMenunggu   = "Menunggu"
+	StatusUsulanDiterima   = "Diterima"
+	StatusUsulanDitolak    = "Ditolak"
+	StatusUsulanDibatalkan = "Dibatalkan"
+)
+
+var errTidakAdaUsulan = errors.New("tidak ada usulan jadwal yang menunggu tanggapan")
+
+// muatJanjiTemuMilik mengambil janji temu dan memastikan masyarakat hanya bisa
+// mengakses janji temu miliknya sendiri. Nil berarti response error sudah dikirim.
+func muatJanjiTemuMilik(c *fiber.Ctx, userID uint, role string) (*models.JanjiTemu, error) {
+	var janjiTemu models.JanjiTemu
+	if err := database.DB.First(&janjiTemu, c.Params("id")).Error; err != nil {
+		return nil, c.Status(http.StatusNotFound).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusNotFound,
+			Status:  "error",
+			Message: "Janji temu not found",
+		})
+	}
+	if role != "admin" && janjiTemu.UserID != userID {
+		return nil, c.Status(http.StatusForbidden).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "You are not authorized to access this janji temu",
+		})
+	}
+	return &janjiTemu, nil
+}
+
+func usulanMenunggu(tx *gorm.DB, janjiTemuID uint) (models.UsulanJadwalJanjiTemu, error) {
+	var usulan models.UsulanJadwalJanjiTemu
+	err := tx.Where("janji_temu_id = ? AND status = ?", janjiTemuID, StatusUsulanMenunggu).
+		Order("created_at desc").First(&usulan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return usulan, errTidakAdaUsulan
+	}
+	return usulan, err
+}
+
+// simpanStatusJanjiTemu menyimpan status baru janji temu sekaligus menaikkan revisinya.
+func simpanStatusJanjiTemu(tx *gorm.DB, janjiTemu *models.JanjiTemu) error {
+	janjiTemu.Revisi++
+	return tx.Model(janjiTemu).Updates(map[string]interface{}{
+		"status": janjiTemu.Status,
+		"revisi": gorm.Expr("revisi + 1"),
+	}).Error
+}
+
+// tutupUsulanMenunggu membatalkan usulan yang masih menunggu, dipakai ketika admin
+// langsung menyetujui atau menolak janji temu.
+func tutupUsulanMenunggu(tx *gorm.DB, janjiTemuID uint, now time.Time) error {
+	return tx.Model(&models.UsulanJadwalJanjiTemu{}).
+		Where("janji_temu_id = ? AND status = ?", janjiTemuID, StatusUsulanMenunggu).
+		Updates(map[string]interface{}{"status": StatusUsulanDibatalkan, "ditanggapi_pada": now}).Error
+}
+
+// stafJanjiTemu mengembalikan ID staf yang menangani janji temu, jika sudah ada.
+func stafJanjiTemu(janjiTemu models.JanjiTemu) *uint {
+	if janjiTemu.KonselorID != nil {
+		return janjiTemu.KonselorID
+	}
+	return janjiTemu.UserIDTolakSetujui
+}
+
+// notifikasiUsulanJadwal mengirim notifikasi ke pihak lain (template kunci) dan ke
+// pelaku aksi (template kunci+"_aktor"), sehingga kedua pihak tahu setiap langkah usulan.
+func notifikasiUsulanJadwal(janjiTemu models.JanjiTemu, aktorID uint, status, kunci string, vars varsJanjiTemu, now time.Time) {
+	db := database.GetGormDBInstance()
+
+	penerima := []uint{janjiTemu.UserID}
+	if stafID := stafJanjiTemu(janjiTemu); stafID != nil && *stafID != janjiTemu.UserID {
+		penerima = append(penerima, *stafID)
+	}
+	for _, penerimaID := range penerima {
+		kunciPenerima := kunci
+		if penerimaID == aktorID {
+			kunciPenerima = kunci + "_aktor"
+		}
+		judul, pesan, err := renderNotifikasi(db, kunciPenerima, penerimaID, vars)
+		if err != nil {
+			log.Printf("Failed to render reschedule notification: %v", err)
+			continue
+		}
+		notificationData := JenisJanjiTemu.Data(PayloadJanjiTemu{JanjiTemuID: janjiTemu.ID, Status: status}, aktorID, pesan, now)
+		if err := kirimNotifikasi(db, penerimaID, judul, pesan, notificationData, now); err != nil {
+			log.Printf("Failed to send reschedule notification: %v", err)
+		}
+	}
+}
+
+// UsulkanJadwalUlangJanjiTemu: admin atau masyarakat mengusulkan waktu baru untuk
+// janji temu. Janji temu berstatus "Diusulkan ulang" sampai pihak lain menanggapi.
+func UsulkanJadwalUlangJanjiTemu(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+	janjiTemu, err := muatJanjiTemuMilik(c, userID, role)
+	if janjiTemu == nil {
+		return err
+	}
+	if janjiTemu.Status != "Belum disetujui" && janjiTemu.Status != "Disetujui" {
+		return c.Status(http.StatusConflict).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusConflict,
+			Status:  "error",
+			Message: "Jadwal hanya bisa diusulkan ulang untuk janji temu berstatus 'Belum disetujui' atau 'Disetujui'",
+		})
+	}
+
+	waktuDimulai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_dimulai"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid format for start time",
+		})
+	}
+	waktuSelesai, err := time.Parse("2006-01-02T15:04:05", c.FormValue("waktu_selesai"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Invalid format for end time",
+		})
+	}
+	if err := validasiWaktuJanjiTemu(waktuDimulai, waktuSelesai); err != nil {
+		return responseErrorJadwal(c, err)
+	}
+	alasan := c.FormValue("alasan")
+	if alasan == "" {
+		return c.Status(http.StatusBadRequest).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusBadRequest,
+			Status:  "error",
+			Message: "Alasan is required",
+		})
+	}
+
+	now := time.Now()
+	usulan := models.UsulanJadwalJanjiTemu{
+		JanjiTemuID:               janjiTemu.ID,
+		DiusulkanOlehID:           userID,
+		PeranPengusul:             role,
+		WaktuDimulaiSebelumnya:    janjiTemu.WaktuDimulai,
+		WaktuSelesaiSebelumnya:    janjiTemu.WaktuSelesai,
+		WaktuDimulaiUsulan:        waktuDimulai,
+		WaktuSelesaiUsulan:        waktuSelesai,
+		StatusJanjiTemuSebelumnya: janjiTemu.Status,
+		Alasan:                    alasan,
+		Status:                    StatusUsulanMenunggu,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Cek bentrok lebih awal agar pihak lain tidak menerima usulan yang mustahil.
+		if janjiTemu.KonselorID != nil {
+			if err := kunciKonselor(tx, *janjiTemu.KonselorID); err != nil {
+				return err
+			}
+			if err := cekBentrokKonselor(tx, *janjiTemu.KonselorID, waktuDimulai, waktuSelesai, janjiTemu.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&usulan).Error; err != nil {
+			return err
+		}
+		janjiTemu.Status = StatusJanjiTemuDiusulkanUlang
+		return simpanStatusJanjiTemu(tx, janjiTemu)
+	})
+	if err != nil {
+		if errors.Is(err, errJadwalBentrok) {
+			return responseErrorJadwal(c, err)
+		}
+		log.Printf("Failed to create reschedule proposal: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to propose new schedule",
+		})
+	}
+
+	notifikasiUsulanJadwal(*janjiTemu, userID, "reschedule_proposed", "usulan_jadwal_diajukan",
+		varsJanjiTemu{WaktuDimulai: waktuDimulai, WaktuSelesai: waktuSelesai, Alasan: alasan},
+		now)
+
+	return c.Status(http.StatusCreated).JSON(helper.ResponseWithData{
+		Code:    http.StatusCreated,
+		Status:  "success",
+		Message: "Usulan jadwal baru berhasil dikirim",
+		Data:    usulan,
+	})
+}
+
+// tanggapiUsulanJadwal memuat usulan yang menunggu dan memastikan yang menanggapi
+// adalah pihak lain, bukan pengusul. Nil berarti response error sudah dikirim.
+func tanggapiUsulanJadwal(c *fiber.Ctx, userID uint, role string) (*models.JanjiTemu, *models.UsulanJadwalJanjiTemu, error) {
+	janjiTemu, err := muatJanjiTemuMilik(c, userID, role)
+	if janjiTemu == nil {
+		return nil, nil, err
+	}
+	usulan, err := usulanMenunggu(database.DB, janjiTemu.ID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Failed to retrieve schedule proposal"
+		if errors.Is(err, errTidakAdaUsulan) {
+			status = http.StatusNotFound
+			message = err.Error()
+		}
+		return nil, nil, c.Status(status).JSON(helper.ResponseWithOutData{
+			Code:    status,
+			Status:  "error",
+			Message: message,
+		})
+	}
+	if usulan.PeranPengusul == role {
+		return nil, nil, c.Status(http.StatusForbidden).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusForbidden,
+			Status:  "error",
+			Message: "Usulan harus ditanggapi oleh pihak lain",
+		})
+	}
+	return janjiTemu, &usulan, nil
+}
+
+// TerimaUsulanJadwalJanjiTemu menerapkan waktu usulan ke janji temu dan
+// menyetujuinya. Pengingat dikirim ulang sesuai waktu baru.
+func TerimaUsulanJadwalJanjiTemu(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+	janjiTemu, usulan, err := tanggapiUsulanJadwal(c, userID, role)
+	if janjiTemu == nil {
+		return err
+	}
+
+	now := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if janjiTemu.KonselorID == nil {
+			// Janji temu tanpa konselor ditangani admin yang terlibat dalam usulan.
+			stafID := usulan.DiusulkanOlehID
+			if role == "admin" {
+				stafID = userID
+			}
+			janjiTemu.KonselorID = &stafID
+		}
+		if role == "admin" {
+			janjiTemu.UserIDTolakSetujui = &userID
+		} else if janjiTemu.UserIDTolakSetujui == nil {
+			janjiTemu.UserIDTolakSetujui = &usulan.DiusulkanOlehID
+		}
+		if err := kunciKonselor(tx, *janjiTemu.KonselorID); err != nil {
+			return err
+		}
+		if err := cekBentrokKonselor(tx, *janjiTemu.KonselorID, usulan.WaktuDimulaiUsulan, usulan.WaktuSelesaiUsulan, janjiTemu.ID); err != nil {
+			return err
+		}
+
+		janjiTemu.WaktuDimulai = usulan.WaktuDimulaiUsulan
+		janjiTemu.WaktuSelesai = usulan.WaktuSelesaiUsulan
+		janjiTemu.JadwalKonselorID = nil
+		janjiTemu.Status = "Disetujui"
+		janjiTemu.Revisi++
+		if err := tx.Omit("User", "UserTolakSetujui", "UsulanJadwal").Save(janjiTemu).Error; err != nil {
+			return err
+		}
+
+		usulan.Status = StatusUsulanDiterima
+		usulan.DitanggapiOlehID = &userID
+		usulan.DitanggapiPada = &now
+		if err := tx.Save(usulan).Error; err != nil {
+			return err
+		}
+		return tx.Where("janji_temu_id = ?", janjiTemu.ID).Delete(&models.PengingatJanjiTemu{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errJadwalBentrok) {
+			return responseErrorJadwal(c, err)
+		}
+		log.Printf("Failed to accept reschedule proposal: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to accept schedule proposal",
+		})
+	}
+
+	notifikasiUsulanJadwal(*janjiTemu, userID, "reschedule_accepted", "usulan_jadwal_diterima",
+		varsJanjiTemu{WaktuDimulai: janjiTemu.WaktuDimulai, WaktuSelesai: janjiTemu.WaktuSelesai},
+		now)
+
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Usulan jadwal diterima",
+		Data:    usulan,
+	})
+}
+
+// TolakUsulanJadwalJanjiTemu menolak usulan; janji temu kembali ke status dan
+// waktu sebelum usulan dibuat.
+func TolakUsulanJadwalJanjiTemu(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+	janjiTemu, usulan, err := tanggapiUsulanJadwal(c, userID, role)
+	if janjiTemu == nil {
+		return err
+	}
+
+	now := time.Now()
+	alasan := c.FormValue("alasan_penolakan")
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		usulan.Status = StatusUsulanDitolak
+		usulan.AlasanPenolakan = alasan
+		usulan.DitanggapiOlehID = &userID
+		usulan.DitanggapiPada = &now
+		if err := tx.Save(usulan).Error; err != nil {
+			return err
+		}
+		janjiTemu.Status = usulan.StatusJanjiTemuSebelumnya
+		return simpanStatusJanjiTemu(tx, janjiTemu)
+	})
+	if err != nil {
+		log.Printf("Failed to decline reschedule proposal: %v", err)
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to decline schedule proposal",
+		})
+	}
+
+	notifikasiUsulanJadwal(*janjiTemu, userID, "reschedule_declined", "usulan_jadwal_ditolak",
+		varsJanjiTemu{WaktuDimulai: janjiTemu.WaktuDimulai, WaktuSelesai: janjiTemu.WaktuSelesai, Alasan: alasan},
+		now)
+
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Usulan jadwal ditolak",
+		Data:    usulan,
+	})
+}
+
+func GetRiwayatUsulanJadwalJanjiTemu(c *fiber.Ctx) error {
+	userID, role, ok := currentUserClaims(c)
+	if !ok {
+		return c.Status(http.StatusUnauthorized).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusUnauthorized,
+			Status:  "error",
+			Message: "Unauthorized",
+		})
+	}
+	janjiTemu, err := muatJanjiTemuMilik(c, userID, role)
+	if janjiTemu == nil {
+		return err
+	}
+
+	var riwayat []models.UsulanJadwalJanjiTemu
+	if err := database.DB.Where("janji_temu_id = ?", janjiTemu.ID).Order("created_at asc").Find(&riwayat).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(helper.ResponseWithOutData{
+			Code:    http.StatusInternalServerError,
+			Status:  "error",
+			Message: "Failed to retrieve schedule proposal history",
+		})
+	}
+	return c.Status(http.StatusOK).JSON(helper.ResponseWithData{
+		Code:    http.StatusOK,
+		Status:  "success",
+		Message: "Riwayat usulan jadwal janji temu",
+		Data:    riwayat,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/middleware"
+	"backend-pedika-fiber/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func siapkanUsulanJadwalTest(t *testing.T) (*fiber.App, models.JanjiTemu) {
+	t.Helper()
+	siapkanOutboxTest(t)
+	if err := database.DB.AutoMigrate(&models.JanjiTemu{}, &models.UsulanJadwalJanjiTemu{}, &models.PengingatJanjiTemu{}, &models.TemplateNotifikasi{}); err != nil {
+		t.Fatal(err)
+	}
+	buatUserTest(t, 1, "warga@example.com", "", "", "")
+	buatUserTest(t, 2, "konselor@example.com", "", "", "")
+	buatUserTest(t, 3, "lain@example.com", "", "", "")
+
+	konselor := uint(2)
+	mulai := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	janjiTemu := buatJanjiTemuTest(t, models.JanjiTemu{UserID: 1, KonselorID: &konselor, UserIDTolakSetujui: &konselor,
+		Status: "Disetujui", WaktuDimulai: mulai, WaktuSelesai: mulai.Add(time.Hour), Revisi: 1})
+	if err := database.DB.Create(&models.PengingatJanjiTemu{JanjiTemuID: janjiTemu.ID, UserID: 1, OffsetMenit: 1440}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	admin := app.Group("/admin", middleware.AdminMiddleware)
+	admin.Put("/usul-ulang-janjitemu/:id", UsulkanJadwalUlangJanjiTemu)
+	masyarakat := app.Group("/masyarakat", middleware.MasyarakatMiddleware)
+	masyarakat.Put("/terima-usulan-janjitemu/:id", TerimaUsulanJadwalJanjiTemu)
+	masyarakat.Put("/tolak-usulan-janjitemu/:id", TolakUsulanJadwalJanjiTemu)
+	return app, janjiTemu
+}
+
+func usulkanJadwalTest(t *testing.T, app *fiber.App, janjiTemuID uint) {
+	t.Helper()
+	status, body := requestTest(t, app, "PUT", "/admin/usul-ulang-janjitemu/"+formatID(janjiTemuID), tokenTest(t, 2, "admin"),
+		"waktu_dimulai=2030-01-08T13:00:00&waktu_selesai=2030-01-08T14:00:00&alasan=Konselor+berhalangan")
+	if status != http.StatusCreated {
+		t.Fatalf("propose status = %d, want 201: %v", status, body)
+	}
+	var janjiTemu models.JanjiTemu
+	database.DB.First(&janjiTemu, janjiTemuID)
+	if janjiTemu.Status != StatusJanjiTemuDiusulkanUlang {
+		t.Fatalf("status after proposal = %q, want %q", janjiTemu.Status, StatusJanjiTemuDiusulkanUlang)
+	}
+}
+
+func TestTerimaUsulanJadwal(t *testing.T) {
+	app, janjiTemu := siapkanUsulanJadwalTest(t)
+	usulkanJadwalTest(t, app, janjiTemu.ID)
+
+	// Pengguna lain tidak boleh menanggapi usulan
+	if status, _ := requestTest(t, app, "PUT", "/masyarakat/terima-usulan-janjitemu/"+formatID(janjiTemu.ID), tokenTest(t, 3, "masyarakat"), ""); status != http.StatusForbidden {
+		t.Errorf("other citizen accept status = %d, want 403", status)
+	}
+
+	status, body := requestTest(t, app, "PUT", "/masyarakat/terima-usulan-janjitemu/"+formatID(janjiTemu.ID), tokenTest(t, 1, "masyarakat"), "")
+	if status != http.StatusOK {
+		t.Fatalf("accept status = %d, want 200: %v", status, body)
+	}
+
+	var hasil models.JanjiTemu
+	database.DB.First(&hasil, janjiTemu.ID)
+	if hasil.Status != "Disetujui" {
+		t.Errorf("status = %q, want Disetujui", hasil.Status)
+	}
+	if want := time.Date(2030, 1, 8, 13, 0, 0, 0, time.UTC); !hasil.WaktuDimulai.Equal(want) {
+		t.Errorf("waktu_dimulai = %v, want %v", hasil.WaktuDimulai, want)
+	}
+	if hasil.Revisi != 3 {
+		t.Errorf("revisi = %d, want 3 (proposal and acceptance each bump it)", hasil.Revisi)
+	}
+	var usulan models.UsulanJadwalJanjiTemu
+	database.DB.Where("janji_temu_id = ?", janjiTemu.ID).First(&usulan)
+	if usulan.Status != StatusUsulanDiterima || usulan.DitanggapiOlehID == nil || *usulan.DitanggapiOlehID != 1 {
+		t.Errorf("unexpected proposal after accept: %+v", usulan)
+	}
+	if got := jumlahPengingatTest(t, janjiTemu.ID); got != 0 {
+		t.Errorf("reminders for the old time = %d, want 0", got)
+	}
+
+	// Usulan yang sudah ditanggapi tidak bisa ditanggapi lagi
+	if status, _ := requestTest(t, app, "PUT", "/masyarakat/tolak-usulan-janjitemu/"+formatID(janjiTemu.ID), tokenTest(t, 1, "masyarakat"), ""); status != http.StatusNotFound {
+		t.Errorf("decline after accept status = %d, want 404", status)
+	}
+}
+
+func TestTolakUsulanJadwal(t *testing.T) {
+	app, janjiTemu := siapkanUsulanJadwalTest(t)
+	usulkanJadwalTest(t, app, janjiTemu.ID)
+
+	status, body := requestTest(t, app, "PUT", "/masyarakat/tolak-usulan-janjitemu/"+formatID(janjiTemu.ID), tokenTest(t, 1, "masyarakat"),
+		"alasan_penolakan=Tidak+bisa+hadir")
+	if status != http.StatusOK {
+		t.Fatalf("decline status = %d, want 200: %v", status, body)
+	}
+
+	var hasil models.JanjiTemu
+	database.DB.First(&hasil, janjiTemu.ID)
+	if hasil.Status != "Disetujui" {
+		t.Errorf("status = %q, want previous status Disetujui", hasil.Status)
+	}
+	if !hasil.WaktuDimulai.Equal(janjiTemu.WaktuDimulai) {
+		t.Errorf("waktu_dimulai = %v, want unchanged %v", hasil.WaktuDimulai, janjiTemu.WaktuDimulai)
+	}
+	var usulan models.UsulanJadwalJanjiTemu
+	database.DB.Where("janji_temu_id = ?", janjiTemu.ID).First(&usulan)
+	if usulan.Status != StatusUsulanDitolak || usulan.AlasanPenolakan != "Tidak bisa hadir" {
+		t.Errorf("unexpected proposal after decline: %+v", usulan)
+	}
+	if got := jumlahPengingatTest(t, janjiTemu.ID); got != 1 {
+		t.Errorf("reminders = %d, want existing reminder kept", got)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"log"
 	"mime/multipart"
 	"os"
+	"path"
+	"strings"
+	"testing"
 
 	"github.com/cloudinary/cloudinary-go"
 	"github.com/cloudinary/cloudinary-go/api/uploader"
@@ -14,6 +17,10 @@ import (
 )
 
 func init() {
+	// Saat go test, variabel lingkungan diatur oleh test (t.Setenv), bukan .env
+	if testing.Testing() {
+		return
+	}
 	err := godotenv.Load()
 	if err != nil {	
 		log.Fatal("Error loading .env file")
@@ -66,3 +73,56 @@ func UploadMultipleFileToCloudinary(files []*multipart.FileHeader) ([]string, er
 
 	return imageURLs, nil
 }
+
+// DeleteFilesFromCloudinary menghapus file yang sudah terunggah berdasarkan URL-nya,
+// misalnya ketika data pemilik file gagal disimpan ke database.
+func DeleteFilesFromCloudinary(urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	cloudName := os.Getenv("CLOUD_NAME")
+	apiKey := os.Getenv("API_KEY")
+	apiSecret := os.Getenv("API_SECRET")
+
+	cldService, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return fmt.Errorf("failed to create Cloudinary service: %v", err)
+	}
+
+	ctx := context.Background()
+	var gagal []string
+	for _, url := range urls {
+		resourceType, publicID, ok := cloudinaryPublicID(url)
+		if !ok {
+			gagal = append(gagal, url)
+			continue
+		}
+		if _, err := cldService.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID, ResourceType: resourceType}); err != nil {
+			gagal = append(gagal, url)
+		}
+	}
+	if len(gagal) > 0 {
+		return fmt.Errorf("failed to delete %d file(s) from Cloudinary: %s", len(gagal), strings.Join(gagal, ", "))
+	}
+	return nil
+}
+
+// cloudinaryPublicID membaca resource type dan public ID dari URL Cloudinary, contoh
+// https://res.cloudinary.com/<cloud>/image/upload/v1712345678/abc123.jpg -> ("image", "abc123").
+func cloudinaryPublicID(url string) (string, string, bool) {
+	sebelum, sesudah, ok := strings.Cut(url, "/upload/")
+	if !ok {
+		return "", "", false
+	}
+	resourceType := path.Base(sebelum)
+	if bagian := strings.SplitN(sesudah, "/", 2); len(bagian) == 2 && len(bagian[0]) > 1 && bagian[0][0] == 'v' && strings.Trim(bagian[0][1:], "0123456789") == "" {
+		sesudah = bagian[1]
+	}
+	if resourceType != "raw" {
+		sesudah = strings.TrimSuffix(sesudah, path.Ext(sesudah))
+	}
+	if sesudah == "" {
+		return "", "", false
+	}
+	return resourceType, sesudah, true
+}
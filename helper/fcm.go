@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	firebase "firebase.google.com/go"
+	"firebase.google.com/go/messaging"
+	"google.golang.org/api/option"
+)
+
+var ErrFCMNotInitialized = errors.New("firebase messaging client is not initialized")
+
+// FCMResult adalah hasil pengiriman ke satu token.
+type FCMResult struct {
+	Token     string
+	MessageID string
+	Error     error
+}
+
+// FCMClient membungkus messaging.Client yang dibuat sekali saat aplikasi start.
+type FCMClient struct {
+	client      *messaging.Client
+	concurrency int
+}
+
+var (
+	fcmClient   *FCMClient
+	fcmClientMu sync.RWMutex
+)
+
+// InitFCM membuat Firebase app dan messaging client dari GOOGLE_APPLICATION_CREDENTIALS.
+// FCM_SEND_CONCURRENCY mengatur jumlah pengiriman paralel untuk multicast (default 20).
+func InitFCM(ctx context.Context) error {
+	opt := option.WithCredentialsFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	app, err := firebase.NewApp(ctx, nil, opt)
+	if err != nil {
+		return err
+	}
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		return err
+	}
+
+	concurrency := 20
+	if value, err := strconv.Atoi(os.Getenv("FCM_SEND_CONCURRENCY")); err == nil && value > 0 {
+		concurrency = value
+	}
+
+	fcmClientMu.Lock()
+	fcmClient = &FCMClient{client: client, concurrency: concurrency}
+	fcmClientMu.Unlock()
+	return nil
+}
+
+func GetFCMClient() (*FCMClient, error) {
+	fcmClientMu.RLock()
+	defer fcmClientMu.RUnlock()
+	if fcmClient == nil {
+		return nil, ErrFCMNotInitialized
+	}
+	return fcmClient, nil
+}
+
+// IsInvalidFCMToken mengenali error FCM yang berarti token tidak akan pernah bisa
+// dipakai lagi (aplikasi di-uninstall atau token rusak). INVALID_ARGUMENT juga
+// dipakai untuk payload yang salah/terlalu besar, jadi hanya dianggap token mati
+// jika detail error menyebut field token. MISMATCHED_CREDENTIAL sengaja tidak
+// termasuk: biasanya kredensial server yang salah, bukan tokennya.
+func IsInvalidFCMToken(err error) bool {
+	if messaging.IsRegistrationTokenNotRegistered(err) {
+		return true
+	}
+	if messaging.IsInvalidArgument(err) {
+		detail := strings.ToLower(err.Error())
+		return strings.Contains(detail, "registration token") || strings.Contains(detail, "message.token")
+	}
+	return false
+}
+
+func (f *FCMClient) Send(ctx context.Context, message *messaging.Message) (string, error) {
+	return f.client.Send(ctx, message)
+}
+
+// SendMulticast mengirim pesan yang sama ke banyak token. Setiap token dikirim
+// sebagai request terpisah secara paralel (seperti SendEachForMulticast), karena
+// endpoint batch lama yang dipakai SendMulticast di SDK ini sudah dimatikan Google.
+func (f *FCMClient) SendMulticast(ctx context.Context, tokens []string, template *messaging.Message) []FCMResult {
+	return f.SendBatch(ctx, MulticastMessages(tokens, template))
+}
+
+// SendBatch mengirim pesan yang berbeda-beda; urutan hasil sama dengan urutan pesan.
+func (f *FCMClient) SendBatch(ctx context.Context, messages []*messaging.Message) []FCMResult {
+	results := make([]FCMResult, len(messages))
+	sem := make(chan struct{}, f.concurrency)
+	var wg sync.WaitGroup
+	for i, message := range messages {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, message *messaging.Message) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			messageID, err := f.client.Send(ctx, message)
+			results[i] = FCMResult{Token: message.Token, MessageID: messageID, Error: err}
+		}(i, message)
+	}
+	wg.Wait()
+	return results
+}
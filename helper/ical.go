@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICalEvent adalah satu VEVENT pada file iCalendar (RFC 5545).
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	// Status: CONFIRMED, TENTATIVE atau CANCELLED.
+	Status   string
+	Sequence int64
+	Stamp    time.Time
+}
+
+// BuildICalendar menyusun VCALENDAR. Waktu di database disimpan sebagai jam lokal
+// tanpa zona; jam tersebut dibaca sebagai waktu di tzid (misal Asia/Jakarta) lalu
+// ditulis dalam UTC, sehingga tidak perlu komponen VTIMEZONE.
+func BuildICalendar(name, tzid string, events []ICalEvent) string {
+	loc := time.UTC
+	if tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//PelitaPena//Layanan Pengaduan//ID")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	if name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(name))
+	}
+	if tzid != "" {
+		writeICalLine(&b, "X-WR-TIMEZONE:"+tzid)
+	}
+
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+event.Stamp.UTC().Format("20060102T150405Z"))
+		if event.AllDay {
+			writeICalLine(&b, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+			writeICalLine(&b, "DTEND;VALUE=DATE:"+event.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeICalLine(&b, "DTSTART:"+formatICalUTC(event.Start, loc))
+			writeICalLine(&b, "DTEND:"+formatICalUTC(event.End, loc))
+		}
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+		if event.Location != "" {
+			writeICalLine(&b, "LOCATION:"+escapeICalText(event.Location))
+		}
+		if event.Status != "" {
+			writeICalLine(&b, "STATUS:"+event.Status)
+		}
+		writeICalLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// formatICalUTC menafsirkan jam dinding t di loc lalu menuliskannya sebagai UTC.
+func formatICalUTC(t time.Time, loc *time.Location) string {
+	lokal := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+	return lokal.UTC().Format("20060102T150405Z")
+}
+
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// writeICalLine menulis satu baris dengan CRLF dan melipat baris lebih dari 75 oktet.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Jangan memotong di tengah karakter UTF-8.
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Baris lanjutan diawali satu spasi.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
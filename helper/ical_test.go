@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestBuildICalendarMenulisUTC(t *testing.T) {
+	// Jam dinding 10:00 di Asia/Jakarta (UTC+7) tersimpan tanpa zona
+	mulai := time.Date(2030, 1, 7, 10, 0, 0, 0, time.UTC)
+	body := BuildICalendar("Kalender", "Asia/Jakarta", []ICalEvent{{
+		UID:      "janjitemu-1@pelitapena",
+		Summary:  "Janji Temu Konsultasi",
+		Start:    mulai,
+		End:      mulai.Add(time.Hour),
+		Status:   "CONFIRMED",
+		Sequence: 3,
+		Stamp:    time.Date(2030, 1, 1, 8, 0, 0, 0, time.FixedZone("WIB", 7*3600)),
+	}})
+
+	for _, baris := range []string{
+		"DTSTART:20300107T030000Z\r\n",
+		"DTEND:20300107T040000Z\r\n",
+		"DTSTAMP:20300101T010000Z\r\n",
+		"SEQUENCE:3\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"X-WR-TIMEZONE:Asia/Jakarta\r\n",
+	} {
+		if !strings.Contains(body, baris) {
+			t.Errorf("calendar missing %q:\n%s", strings.TrimSpace(baris), body)
+		}
+	}
+	if strings.Contains(body, "TZID") || strings.Contains(body, "VTIMEZONE") {
+		t.Errorf("calendar must use UTC times only:\n%s", body)
+	}
+}
+
+func TestBuildICalendarAcaraDibatalkan(t *testing.T) {
+	body := BuildICalendar("", "", []ICalEvent{{
+		UID:      "event-2@pelitapena",
+		Summary:  "Sosialisasi, (Dibatalkan)",
+		Start:    time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC),
+		AllDay:   true,
+		Status:   "CANCELLED",
+		Sequence: 1,
+	}})
+
+	for _, baris := range []string{
+		"DTSTART;VALUE=DATE:20300201\r\n",
+		"DTEND;VALUE=DATE:20300202\r\n",
+		"SUMMARY:Sosialisasi\\, (Dibatalkan)\r\n",
+		"STATUS:CANCELLED\r\n",
+		"SEQUENCE:1\r\n",
+	} {
+		if !strings.Contains(body, baris) {
+			t.Errorf("calendar missing %q:\n%s", strings.TrimSpace(baris), body)
+		}
+	}
+}
+
+func TestWriteICalLineMelipatBarisPanjang(t *testing.T) {
+	var b strings.Builder
+	writeICalLine(&b, "DESCRIPTION:"+strings.Repeat("é", 60))
+
+	for _, baris := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(baris) > 75 {
+			t.Errorf("line longer than 75 octets: %d", len(baris))
+		}
+		if !strings.HasPrefix(baris, " ") && !strings.HasPrefix(baris, "DESCRIPTION:") {
+			t.Errorf("continuation line must start with a space: %q", baris)
+		}
+	}
+	if got := strings.ReplaceAll(b.String(), "\r\n ", ""); got != "DESCRIPTION:"+strings.Repeat("é", 60)+"\r\n" {
+		t.Errorf("unfolded line changed: %q", got)
+	}
+}
@@ -0,0 +1,279 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Email adalah satu pesan email dengan versi teks dan HTML.
+type Email struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim email. Implementasi dipilih saat start lewat MAIL_DRIVER.
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// SMTPMailer mengirim lewat server SMTP (EMAIL_SENDER, EMAIL_PASSWORD, SMTP_HOST, SMTP_PORT).
+// Seluruh percakapan SMTP dibatasi Timeout (default 30 detik) atau deadline ctx,
+// mana yang lebih dulu, agar server yang menggantung tidak menahan pemanggil.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, email Email) error {
+	if m.Host == "" {
+		return errors.New("SMTP_HOST is not configured")
+	}
+	msg, err := buildMIME(m.From, email)
+	if err != nil {
+		return err
+	}
+
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := m.kirim(ctx, email.To, msg); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("smtp: %w", ctx.Err())
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *SMTPMailer) kirim(ctx context.Context, to []string, msg []byte) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// Putuskan koneksi jika ctx dibatalkan sebelum deadline tercapai
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// MemoryMailer menyimpan email di memori; dipakai untuk pengujian.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, email Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+// Sent mengembalikan salinan email yang sudah "dikirim".
+func (m *MemoryMailer) Sent() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.sent...)
+}
+
+// FileMailer menulis setiap email sebagai file .eml di Dir; berguna di lingkungan
+// development tanpa server SMTP.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(ctx context.Context, email Email) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	msg, err := buildMIME(m.From, email)
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.Dir, name), msg, 0o644)
+}
+
+// buildMIME menyusun pesan multipart/alternative (teks + HTML).
+func buildMIME(from string, email Email) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", email.Text},
+		{"text/html; charset=UTF-8", email.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mimeEncodeHeader(email.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func mimeEncodeHeader(value string) string {
+	value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+	for _, r := range value {
+		if r > 127 {
+			return "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(value)) + "?="
+		}
+	}
+	return value
+}
+
+var (
+	mailer   Mailer
+	mailerMu sync.RWMutex
+)
+
+// InitMailer memilih implementasi dari MAIL_DRIVER: "smtp" (default, SMTP_TIMEOUT
+// misalnya "20s"), "file" (MAIL_FILE_DIR, default tmp/mail) atau "memory".
+func InitMailer() Mailer {
+	from := os.Getenv("EMAIL_SENDER")
+	var m Mailer
+	switch os.Getenv("MAIL_DRIVER") {
+	case "memory":
+		m = &MemoryMailer{}
+	case "file":
+		dir := os.Getenv("MAIL_FILE_DIR")
+		if dir == "" {
+			dir = filepath.Join("tmp", "mail")
+		}
+		m = &FileMailer{Dir: dir, From: from}
+	default:
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		timeout, _ := time.ParseDuration(os.Getenv("SMTP_TIMEOUT"))
+		m = &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: from,
+			Password: os.Getenv("EMAIL_PASSWORD"),
+			From:     from,
+			Timeout:  timeout,
+		}
+	}
+	SetMailer(m)
+	return m
+}
+
+// SetMailer mengganti mailer aktif, misalnya dengan MemoryMailer saat pengujian.
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	mailer = m
+	mailerMu.Unlock()
+}
+
+// GetMailer mengembalikan mailer aktif; jika belum diinisialisasi, dibuat dari env.
+func GetMailer() Mailer {
+	mailerMu.RLock()
+	m := mailer
+	mailerMu.RUnlock()
+	if m == nil {
+		return InitMailer()
+	}
+	return m
+}
+
+// AppBaseURL adalah alamat aplikasi web untuk link di email (APP_BASE_URL).
+func AppBaseURL() string {
+	if base := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/"); base != "" {
+		return base
+	}
+	return "http://localhost:3000"
+}
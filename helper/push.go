@@ -0,0 +1,152 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"firebase.google.com/go/messaging"
+)
+
+var ErrPushProviderNotSet = errors.New("push provider is not initialized")
+
+// PushProvider mengirim push notification. Implementasi dipilih saat start lewat
+// PUSH_DRIVER sehingga handler bisa diuji tanpa Firebase.
+type PushProvider interface {
+	Send(ctx context.Context, message *messaging.Message) (string, error)
+	// SendBatch mengirim pesan yang berbeda-beda; urutan hasil sama dengan urutan pesan.
+	SendBatch(ctx context.Context, messages []*messaging.Message) []FCMResult
+}
+
+// MulticastMessages menyalin template untuk setiap token.
+func MulticastMessages(tokens []string, template *messaging.Message) []*messaging.Message {
+	messages := make([]*messaging.Message, len(tokens))
+	for i, token := range tokens {
+		message := *template
+		message.Token = token
+		message.Topic = ""
+		message.Condition = ""
+		messages[i] = &message
+	}
+	return messages
+}
+
+// MemoryPushProvider mencatat semua pesan di memori tanpa mengirim apa pun, agar
+// test integrasi bisa memeriksa notifikasi yang dihasilkan handler. Token yang ada
+// di Failures akan mengembalikan error tersebut.
+type MemoryPushProvider struct {
+	mu       sync.Mutex
+	sent     []*messaging.Message
+	Failures map[string]error
+}
+
+func (p *MemoryPushProvider) Send(ctx context.Context, message *messaging.Message) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err, ok := p.Failures[message.Token]; ok {
+		return "", err
+	}
+	p.sent = append(p.sent, message)
+	return fmt.Sprintf("memory-%d", len(p.sent)), nil
+}
+
+func (p *MemoryPushProvider) SendBatch(ctx context.Context, messages []*messaging.Message) []FCMResult {
+	results := make([]FCMResult, len(messages))
+	for i, message := range messages {
+		messageID, err := p.Send(ctx, message)
+		results[i] = FCMResult{Token: message.Token, MessageID: messageID, Error: err}
+	}
+	return results
+}
+
+// Sent mengembalikan salinan pesan yang sudah dicatat.
+func (p *MemoryPushProvider) Sent() []*messaging.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*messaging.Message(nil), p.sent...)
+}
+
+func (p *MemoryPushProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = nil
+}
+
+// LogPushProvider hanya menulis pesan ke log; cocok untuk development tanpa
+// kredensial Firebase.
+type LogPushProvider struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (p *LogPushProvider) Send(ctx context.Context, message *messaging.Message) (string, error) {
+	p.mu.Lock()
+	p.count++
+	messageID := fmt.Sprintf("log-%d", p.count)
+	p.mu.Unlock()
+
+	title := ""
+	if message.Notification != nil {
+		title = message.Notification.Title
+	}
+	log.Printf("[push %s] token=%s type=%s title=%q", messageID, maskToken(message.Token), message.Data["type"], title)
+	return messageID, nil
+}
+
+func (p *LogPushProvider) SendBatch(ctx context.Context, messages []*messaging.Message) []FCMResult {
+	results := make([]FCMResult, len(messages))
+	for i, message := range messages {
+		messageID, err := p.Send(ctx, message)
+		results[i] = FCMResult{Token: message.Token, MessageID: messageID, Error: err}
+	}
+	return results
+}
+
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return token[:4] + "..." + token[len(token)-4:]
+}
+
+var (
+	pushProvider   PushProvider
+	pushProviderMu sync.RWMutex
+)
+
+// InitPushProvider memilih penyedia dari PUSH_DRIVER: "fcm" (default), "log" atau
+// "memory".
+func InitPushProvider(ctx context.Context) error {
+	switch os.Getenv("PUSH_DRIVER") {
+	case "memory":
+		SetPushProvider(&MemoryPushProvider{})
+	case "log":
+		SetPushProvider(&LogPushProvider{})
+	default:
+		if err := InitFCM(ctx); err != nil {
+			return err
+		}
+		client, _ := GetFCMClient()
+		SetPushProvider(client)
+	}
+	return nil
+}
+
+// SetPushProvider mengganti penyedia aktif, misalnya dengan MemoryPushProvider di test.
+func SetPushProvider(p PushProvider) {
+	pushProviderMu.Lock()
+	pushProvider = p
+	pushProviderMu.Unlock()
+}
+
+func GetPushProvider() (PushProvider, error) {
+	pushProviderMu.RLock()
+	defer pushProviderMu.RUnlock()
+	if pushProvider == nil {
+		return nil, ErrPushProviderNotSet
+	}
+	return pushProvider, nil
+}
@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"log"
+	"sync"
+)
+
+// RealtimeEvent adalah satu event yang dikirim ke klien lewat stream (SSE).
+type RealtimeEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// RealtimeHub adalah pub/sub untuk event realtime. Implementasi bawaan berjalan di
+// dalam proses; untuk beberapa instance server bisa diganti dengan broker (Redis,
+// NATS, ...) lewat SetRealtimeHub tanpa mengubah handler.
+type RealtimeHub interface {
+	Publish(topic string, event RealtimeEvent)
+	// Subscribe mendaftar ke satu atau lebih topik. Fungsi yang dikembalikan wajib
+	// dipanggil saat klien selesai; setelah itu channel ditutup.
+	Subscribe(topics ...string) (<-chan RealtimeEvent, func())
+}
+
+// realtimeBuffer: event untuk subscriber yang lambat dibuang jika buffer penuh,
+// supaya publisher (handler HTTP) tidak pernah tertahan.
+const realtimeBuffer = 32
+
+type memorySubscriber struct {
+	ch     chan RealtimeEvent
+	topics []string
+}
+
+// MemoryRealtimeHub adalah RealtimeHub di dalam proses.
+type MemoryRealtimeHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*memorySubscriber]struct{}
+}
+
+func NewMemoryRealtimeHub() *MemoryRealtimeHub {
+	return &MemoryRealtimeHub{subscribers: make(map[string]map[*memorySubscriber]struct{})}
+}
+
+func (h *MemoryRealtimeHub) Publish(topic string, event RealtimeEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers[topic] {
+		select {
+		case sub.ch <- event:
+		default:
+			log.Printf("Dropping realtime event %s for slow subscriber on %s", event.Type, topic)
+		}
+	}
+}
+
+func (h *MemoryRealtimeHub) Subscribe(topics ...string) (<-chan RealtimeEvent, func()) {
+	sub := &memorySubscriber{ch: make(chan RealtimeEvent, realtimeBuffer), topics: topics}
+
+	h.mu.Lock()
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = make(map[*memorySubscriber]struct{})
+		}
+		h.subscribers[topic][sub] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			for _, topic := range sub.topics {
+				delete(h.subscribers[topic], sub)
+				if len(h.subscribers[topic]) == 0 {
+					delete(h.subscribers, topic)
+				}
+			}
+			h.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+	return sub.ch, unsubscribe
+}
+
+var (
+	realtimeHub   RealtimeHub = NewMemoryRealtimeHub()
+	realtimeHubMu sync.RWMutex
+)
+
+// SetRealtimeHub mengganti hub aktif, misalnya dengan implementasi berbasis broker.
+func SetRealtimeHub(h RealtimeHub) {
+	realtimeHubMu.Lock()
+	realtimeHub = h
+	realtimeHubMu.Unlock()
+}
+
+func GetRealtimeHub() RealtimeHub {
+	realtimeHubMu.RLock()
+	defer realtimeHubMu.RUnlock()
+	return realtimeHub
+}
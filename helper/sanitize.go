@@ -0,0 +1,164 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldPolicy menentukan bagaimana field sensitif ditampilkan untuk sebuah role.
+// Field rahasia (password, token) selalu dibuang, apapun role-nya.
+type FieldPolicy struct {
+	// CanReveal: role boleh meminta nilai asli (reveal) dari field yang disamarkan.
+	CanReveal bool
+}
+
+// RolePolicies berisi kebijakan per role. Role yang tidak terdaftar
+// diperlakukan paling ketat (selalu disamarkan, tidak bisa reveal).
+var RolePolicies = map[string]FieldPolicy{
+	"admin":      {CanReveal: true},
+	"masyarakat": {CanReveal: false},
+}
+
+// secretFields tidak pernah boleh keluar di response API.
+var secretFields = map[string]bool{
+	"password":           true,
+	"notification_token": true,
+}
+
+// maskedFields adalah identitas yang disamarkan secara default (key = nama field JSON).
+var maskedFields = map[string]func(string) string{
+	"nik":           MaskNIK,
+	"nik_korban":    MaskNIK,
+	"nik_pelaku":    MaskNIK,
+	"phone_number":  MaskPhone,
+	"no_telepon":    MaskPhone,
+	"alamat":        MaskAddress,
+	"alamat_korban": MaskAddress,
+	"alamat_pelaku": MaskAddress,
+	"alamat_detail": MaskAddress,
+}
+
+// SanitizeForRole mengubah data menjadi struktur JSON generik lalu membuang field
+// rahasia dan menyamarkan identitas sesuai kebijakan role. Jika reveal diminta dan
+// role diizinkan, nilai asli dipertahankan dan nama field yang dibuka dikembalikan
+// agar pemanggil bisa mencatatnya ke audit log.
+func SanitizeForRole(data any, role string, reveal bool) (any, []string, error) {
+	policy := RolePolicies[role]
+	doReveal := reveal && policy.CanReveal
+
+	generic, err := toGenericJSON(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revealed := map[string]bool{}
+	sanitized := walkSanitize(generic, !doReveal, revealed)
+
+	fields := make([]string, 0, len(revealed))
+	for field := range revealed {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return sanitized, fields, nil
+}
+
+// SanitizeOwn dipakai ketika pemanggil adalah pemilik data (profil sendiri, laporan
+// sendiri): identitas tidak disamarkan, tetapi field rahasia tetap dibuang.
+func SanitizeOwn(data any) (any, error) {
+	generic, err := toGenericJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return walkSanitize(generic, false, map[string]bool{}), nil
+}
+
+func toGenericJSON(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response data: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return generic, nil
+}
+
+func walkSanitize(value any, mask bool, revealed map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if secretFields[key] {
+				delete(v, key)
+				continue
+			}
+			if maskFn, ok := maskedFields[key]; ok {
+				str := scalarToString(field)
+				if str == "" {
+					continue
+				}
+				if mask {
+					v[key] = maskFn(str)
+				} else {
+					revealed[key] = true
+				}
+				continue
+			}
+			v[key] = walkSanitize(field, mask, revealed)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = walkSanitize(v[i], mask, revealed)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func scalarToString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		if v.String() == "0" {
+			return ""
+		}
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// MaskNIK hanya menampilkan 4 digit terakhir NIK.
+func MaskNIK(nik string) string {
+	return maskKeepLast(nik, 4)
+}
+
+// MaskPhone hanya menampilkan 3 digit terakhir nomor telepon.
+func MaskPhone(phone string) string {
+	return maskKeepLast(phone, 3)
+}
+
+// MaskAddress hanya menampilkan kata pertama alamat (biasanya nama jalan/desa).
+func MaskAddress(address string) string {
+	words := strings.Fields(address)
+	if len(words) <= 1 {
+		return "***"
+	}
+	return words[0] + " ***"
+}
+
+func maskKeepLast(value string, keep int) string {
+	runes := []rune(value)
+	if len(runes) <= keep {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
+}
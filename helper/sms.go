@@ -0,0 +1,136 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+var ErrMessagingNotConfigured = errors.New("SMS/WhatsApp provider is not configured (SMS_DRIVER)")
+
+// TextMessage adalah pesan singkat untuk SMS atau WhatsApp.
+type TextMessage struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+	Body    string `json:"body"`
+}
+
+// MessagingProvider mengirim pesan SMS/WhatsApp lewat penyedia tertentu.
+type MessagingProvider interface {
+	Send(ctx context.Context, message TextMessage) error
+}
+
+// HTTPMessagingProvider meneruskan pesan sebagai JSON ke gateway penyedia
+// (SMS_API_URL) dengan header Authorization: Bearer SMS_API_KEY.
+type HTTPMessagingProvider struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func (p *HTTPMessagingProvider) Send(ctx context.Context, message TextMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("messaging provider returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FakeMessagingProvider tidak mengirim apa pun; pesan dicatat di memori untuk
+// pengujian dan development. Isi pesan tidak ditulis ke log karena bisa memuat
+// alasan penolakan atau jadwal janji temu.
+type FakeMessagingProvider struct {
+	mu   sync.Mutex
+	sent []TextMessage
+}
+
+func (p *FakeMessagingProvider) Send(ctx context.Context, message TextMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, message)
+	log.Printf("[fake %s] to %s (%d chars)", message.Channel, MaskPhone(message.To), len(message.Body))
+	return nil
+}
+
+// Sent mengembalikan salinan pesan yang sudah dicatat.
+func (p *FakeMessagingProvider) Sent() []TextMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]TextMessage(nil), p.sent...)
+}
+
+var (
+	messagingProvider   MessagingProvider
+	messagingProviderMu sync.RWMutex
+)
+
+// InitMessagingProvider memilih penyedia dari SMS_DRIVER: "http" (butuh SMS_API_URL)
+// atau "fake" (hanya untuk development/test). Tanpa SMS_DRIVER cadangan SMS/WhatsApp
+// nonaktif; pesan tidak pernah dianggap terkirim tanpa penyedia sungguhan.
+func InitMessagingProvider() {
+	switch driver := os.Getenv("SMS_DRIVER"); driver {
+	case "http":
+		if os.Getenv("SMS_API_URL") == "" {
+			log.Println("WARNING: SMS_DRIVER=http but SMS_API_URL is empty; SMS/WhatsApp fallback disabled")
+			SetMessagingProvider(nil)
+			return
+		}
+		SetMessagingProvider(&HTTPMessagingProvider{
+			URL:    os.Getenv("SMS_API_URL"),
+			APIKey: os.Getenv("SMS_API_KEY"),
+			Client: &http.Client{Timeout: 15 * time.Second},
+		})
+	case "fake":
+		log.Println("WARNING: SMS_DRIVER=fake; SMS/WhatsApp messages are recorded but never delivered")
+		SetMessagingProvider(&FakeMessagingProvider{})
+	case "":
+		log.Println("WARNING: SMS_DRIVER is not set; SMS/WhatsApp fallback disabled")
+		SetMessagingProvider(nil)
+	default:
+		log.Printf("WARNING: unknown SMS_DRIVER %q; SMS/WhatsApp fallback disabled", driver)
+		SetMessagingProvider(nil)
+	}
+}
+
+func SetMessagingProvider(p MessagingProvider) {
+	messagingProviderMu.Lock()
+	messagingProvider = p
+	messagingProviderMu.Unlock()
+}
+
+func GetMessagingProvider() (MessagingProvider, error) {
+	messagingProviderMu.RLock()
+	defer messagingProviderMu.RUnlock()
+	if messagingProvider == nil {
+		return nil, ErrMessagingNotConfigured
+	}
+	return messagingProvider, nil
+}
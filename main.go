@@ -2,8 +2,11 @@ package main
 
 import (
 	"backend-pedika-fiber/database"
+	"backend-pedika-fiber/handlers"
+	"backend-pedika-fiber/helper"
 	"backend-pedika-fiber/migration"
 	"backend-pedika-fiber/routes"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -19,6 +22,20 @@ func main() {
 	database.GetDBInstance()
 	migration.RunMigration()
 
+	// Pilih penyedia push (PUSH_DRIVER: fcm, log, memory); FCM diinisialisasi sekali
+	if err := helper.InitPushProvider(context.Background()); err != nil {
+		log.Printf("Failed to initialize push provider: %v", err)
+	}
+
+	// Pilih implementasi mailer (MAIL_DRIVER) dan penyedia SMS/WhatsApp (SMS_DRIVER)
+	helper.InitMailer()
+	helper.InitMessagingProvider()
+
+	// Jalankan scheduler pengingat janji temu
+	handlers.StartJanjiTemuReminderScheduler()
+	handlers.StartNotificationOutboxWorker()
+	handlers.StartKampanyeNotifikasiWorker()
+
 	// Atur routing
 	routes.SetAuthRoutes(app)
 	routes.SetAdminRoutes(app)
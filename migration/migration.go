@@ -4,9 +4,12 @@ import (
 	"backend-pedika-fiber/database"
 	"backend-pedika-fiber/models"
 	"log"
+
+	"gorm.io/gorm"
 )
 
 func RunMigration() {
+	migrasiPerangkat := !database.DB.Migrator().HasTable(&models.UserDevice{})
 	err := database.DB.AutoMigrate(
 		&models.User{},
 		&models.ViolenceCategory{},
@@ -19,10 +22,70 @@ func RunMigration() {
 		&models.Event{},
 		&models.JanjiTemu{},
 		&models.Notification{},
-		&models.ReportAdmin{})
+		&models.ReportAdmin{},
+		&models.SensitiveDataAccess{},
+		&models.KebutuhanKorban{},
+		&models.DokumentasiFile{},
+		&models.JadwalKonselor{},
+		&models.PengingatJanjiTemu{},
+		&models.KalenderFeed{},
+		&models.UsulanJadwalJanjiTemu{},
+		&models.SesiKonseling{},
+		&models.ProfilKonselor{},
+		&models.NotificationOutbox{},
+		&models.UserDevice{},
+		&models.PreferensiNotifikasi{},
+		&models.PendaftaranEvent{},
+		&models.KampanyeNotifikasi{},
+		&models.TemplateNotifikasi{},
+		&models.LanggananNotifikasiAdmin{})
 	// &models.Notification{}) unutk Tabel Notifciation
 	if err != nil {
 		log.Println(err)
 	}
 
+	migrateLegacyDokumentasi(database.DB, "korbans", "korban")
+	migrateLegacyDokumentasi(database.DB, "pelakus", "pelaku")
+	if migrasiPerangkat {
+		migrateLegacyNotificationToken(database.DB)
+	}
+}
+
+// migrateLegacyNotificationToken menyalin users.notification_token (satu token per
+// user) ke tabel user_devices. Hanya dijalankan saat tabel user_devices baru dibuat.
+func migrateLegacyNotificationToken(db *gorm.DB) {
+	err := db.Exec(
+		"INSERT IGNORE INTO user_devices (user_id, token, platform, app_version, active, last_seen_at, created_at, updated_at) " +
+			"SELECT id, notification_token, '', '', true, updated_at, NOW(), NOW() FROM users " +
+			"WHERE notification_token IS NOT NULL AND notification_token <> ''",
+	).Error
+	if err != nil {
+		log.Printf("Failed to migrate legacy notification tokens: %v", err)
+	}
+}
+
+// migrateLegacyDokumentasi memindahkan kolom lama dokumentasi_pelaku (satu URL)
+// ke tabel dokumentasi_files, lalu menghapus kolom lama. DROP COLUMN di MySQL
+// melakukan commit implisit, jadi INSERT bisa sudah tersimpan walau DROP gagal;
+// baris yang sudah ada dilewati agar migrasi aman dijalankan ulang.
+func migrateLegacyDokumentasi(db *gorm.DB, table, ownerType string) {
+	if !db.Migrator().HasColumn(table, "dokumentasi_pelaku") {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO dokumentasi_files (owner_id, owner_type, url, caption, created_at) "+
+				"SELECT t.id, ?, t.dokumentasi_pelaku, '', t.created_at FROM "+table+" t"+
+				" WHERE t.dokumentasi_pelaku IS NOT NULL AND t.dokumentasi_pelaku <> ''"+
+				" AND NOT EXISTS (SELECT 1 FROM dokumentasi_files d"+
+				" WHERE d.owner_id = t.id AND d.owner_type = ? AND d.url = t.dokumentasi_pelaku)",
+			ownerType, ownerType,
+		).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(table, "dokumentasi_pelaku")
+	})
+	if err != nil {
+		log.Printf("Failed to migrate legacy dokumentasi for %s: %v", table, err)
+	}
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// DokumentasiFile adalah lampiran dokumentasi milik korban atau pelaku
+// (polymorphic: owner_type = "korban" / "pelaku").
+type DokumentasiFile struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OwnerID   uint      `gorm:"not null;index:idx_dokumentasi_owner" json:"owner_id"`
+	OwnerType string    `gorm:"size:20;not null;index:idx_dokumentasi_owner" json:"owner_type"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	Caption   string    `json:"caption"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,14 +2,21 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Event dihapus secara soft delete agar feed kalender bisa mengirimnya sebagai
+// VEVENT yang dibatalkan. Revisi dinaikkan setiap kali event diubah dan dipakai
+// sebagai SEQUENCE iCalendar.
 type Event struct {
-	ID                 uint      `json:"id" gorm:"primaryKey"`
-	NamaEvent          string    `json:"nama_event"`
-	DeskripsiEvent     string    `json:"deskripsi_event"`
-	ThumbnailEvent     string    `json:"thumbnail_event"`
-	TanggalPelaksanaan time.Time `json:"tanggal_pelaksanaan"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	NamaEvent          string         `json:"nama_event"`
+	DeskripsiEvent     string         `json:"deskripsi_event"`
+	ThumbnailEvent     string         `json:"thumbnail_event"`
+	TanggalPelaksanaan time.Time      `json:"tanggal_pelaksanaan"`
+	Revisi             uint           `json:"-" gorm:"not null;default:0"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
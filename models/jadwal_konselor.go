@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// JadwalKonselor adalah slot ketersediaan yang dipublikasikan admin/konselor
+// dan bisa dipesan masyarakat sebagai janji temu.
+type JadwalKonselor struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Konselor     User      `json:"konselor" gorm:"foreignKey:KonselorID"`
+	KonselorID   uint      `gorm:"not null;index" json:"konselor_id"`
+	WaktuMulai   time.Time `gorm:"not null;index" json:"waktu_mulai"`
+	WaktuSelesai time.Time `gorm:"not null" json:"waktu_selesai"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
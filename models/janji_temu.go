@@ -3,17 +3,22 @@ package models
 import "time"
 
 type JanjiTemu struct {
-	ID                  uint      `json:"id" gorm:"primaryKey"`
-	User                User      `json:"user" gorm:"foreignKey:UserID"`
-	UserID              uint      `json:"user_id"`
-	WaktuDimulai        time.Time `json:"waktu_dimulai"`
-	WaktuSelesai        time.Time `json:"waktu_selesai"`
-	KeperluanKonsultasi string    `json:"keperluan_konsultasi"`
-	Status              string    `json:"status"`
-	UserTolakSetujui    User      `json:"user_tolak_setujui" gorm:"foreignKey:UserIDTolakSetujui"`
-	UserIDTolakSetujui  *uint     `json:"userid_tolak_setujui,omitempty"`
-	AlasanDitolak       string    `json:"alasan_ditolak" gorm:"column:alasan_ditolak"`
-	AlasanDibatalkan    string    `json:"alasan_dibatalkan" gorm:"column:alasan_dibatalkan"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ID                  uint                    `json:"id" gorm:"primaryKey"`
+	User                User                    `json:"user" gorm:"foreignKey:UserID"`
+	UserID              uint                    `json:"user_id"`
+	WaktuDimulai        time.Time               `json:"waktu_dimulai"`
+	WaktuSelesai        time.Time               `json:"waktu_selesai"`
+	KeperluanKonsultasi string                  `json:"keperluan_konsultasi"`
+	Status              string                  `json:"status"`
+	UserTolakSetujui    User                    `json:"user_tolak_setujui" gorm:"foreignKey:UserIDTolakSetujui"`
+	UserIDTolakSetujui  *uint                   `json:"userid_tolak_setujui,omitempty"`
+	AlasanDitolak       string                  `json:"alasan_ditolak" gorm:"column:alasan_ditolak"`
+	AlasanDibatalkan    string                  `json:"alasan_dibatalkan" gorm:"column:alasan_dibatalkan"`
+	KonselorID          *uint                   `json:"konselor_id,omitempty" gorm:"index"`
+	JadwalKonselorID    *uint                   `json:"jadwal_konselor_id,omitempty" gorm:"index"`
+	NoRegistrasi        *string                 `json:"no_registrasi,omitempty" gorm:"size:191;index"`
+	UsulanJadwal        []UsulanJadwalJanjiTemu `json:"usulan_jadwal,omitempty" gorm:"foreignKey:JanjiTemuID"`
+	Revisi              uint                    `json:"-" gorm:"not null;default:0"` // naik setiap perubahan waktu/status, untuk SEQUENCE iCalendar
+	CreatedAt           time.Time               `json:"created_at"`
+	UpdatedAt           time.Time               `json:"updated_at"`
 }
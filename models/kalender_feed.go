@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// KalenderFeed menyimpan token rahasia per user untuk URL feed iCalendar.
+type KalenderFeed struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	Token     string    `gorm:"size:64;not null;uniqueIndex" json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
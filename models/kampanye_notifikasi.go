@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// KampanyeNotifikasi adalah pengumuman dari admin ke banyak masyarakat sekaligus
+// (semua atau segmen tertentu). Penerima diproses bertahap oleh worker; Kursor
+// menyimpan user ID terakhir yang sudah diantrekan.
+type KampanyeNotifikasi struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Judul          string     `gorm:"not null" json:"judul"`
+	Isi            string     `gorm:"type:text;not null" json:"isi"`
+	DeepLink       string     `json:"deep_link"`
+	Segmen         string     `gorm:"size:30;not null" json:"segmen"`
+	NilaiSegmen    string     `json:"nilai_segmen"`
+	JadwalKirim    time.Time  `gorm:"index" json:"jadwal_kirim"`
+	Status         string     `gorm:"size:20;not null;index" json:"status"`
+	Kursor         uint       `gorm:"not null;default:0" json:"-"`
+	JumlahPenerima int        `gorm:"not null;default:0" json:"jumlah_penerima"`
+	DibuatOlehID   uint       `json:"dibuat_oleh_id"`
+	SelesaiPada    *time.Time `json:"selesai_pada"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// KebutuhanKorban adalah rencana layanan untuk satu kebutuhan korban
+// (medis, psikososial, hukum, rumah aman, ekonomi).
+type KebutuhanKorban struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	KorbanID            uint       `gorm:"not null;index" json:"korban_id"`
+	JenisKebutuhan      string     `gorm:"size:30;not null" json:"jenis_kebutuhan"`
+	Deskripsi           string     `gorm:"type:text" json:"deskripsi"`
+	LayananDirencanakan string     `gorm:"type:text" json:"layanan_direncanakan"`
+	PenanggungJawab     string     `json:"penanggung_jawab"`
+	TenggatWaktu        *time.Time `json:"tenggat_waktu"`
+	Status              string     `gorm:"size:20;default:'Direncanakan'" json:"status"`
+	WaktuSelesai        *time.Time `json:"waktu_selesai"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
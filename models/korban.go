@@ -21,7 +21,9 @@ type Korban struct {
 	Kebangsaan           string    `json:"kebangsaan"`
 	HubunganDenganKorban string    `json:"hubungan_dengan_pelaku"`
 	KeteranganLainnya    string    `json:"keterangan_lainnya"`
-	DokumentasiPelaku    string    `json:"dokumentasi_korban"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
+
+	Dokumentasi []DokumentasiFile `json:"dokumentasi_korban" gorm:"polymorphic:Owner;polymorphicValue:korban"`
+	Kebutuhan   []KebutuhanKorban `json:"kebutuhan,omitempty" gorm:"foreignKey:KorbanID"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LanggananNotifikasiAdmin menyimpan pilihan satu admin untuk satu jenis peristiwa
+// (laporan baru, janji temu baru, ...). Admin tanpa baris untuk suatu peristiwa
+// dianggap berlangganan penuh. Aktif dan Push sengaja tanpa default di GORM supaya
+// nilai false ikut ditulis saat insert.
+type LanggananNotifikasiAdmin struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	AdminID   uint   `gorm:"not null;uniqueIndex:idx_langganan_admin_peristiwa" json:"admin_id"`
+	Peristiwa string `gorm:"size:40;not null;uniqueIndex:idx_langganan_admin_peristiwa" json:"peristiwa"`
+	Aktif     bool   `gorm:"not null" json:"aktif"`
+	// Push: jika false, peringatan hanya disimpan di inbox tanpa push notification
+	Push bool `gorm:"not null" json:"push"`
+	// KategoriKekerasan: JSON array ID kategori; kosong berarti semua kategori.
+	// Hanya berlaku untuk peristiwa yang terkait laporan.
+	KategoriKekerasan string    `gorm:"type:json" json:"kategori_kekerasan"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -6,17 +6,25 @@ import "time"
 type Notification struct {
     ID        uint      `gorm:"primaryKey" json:"id"`
     UserID    uint      `gorm:"not null;index" json:"user_id"` // Foreign key ke users
-    Type      string    `gorm:"not null" json:"type"`          // report_status, chat, appointment, INIII BARU 3 KALO MAU DITAMBAH JAN LUPA HANDLE FRONT END JUGA NOTIFICATIONS SCREEN.
+    Type      string    `gorm:"not null" json:"type"`          // jenis notifikasi; daftar lengkap di GET /api/notification-schema
     Title     string    `gorm:"not null" json:"title"`
     Body      string    `gorm:"not null" json:"body"`
     Data      string    `gorm:"type:json" json:"data"`         // JSON dari Stucut FCMNotificationData
     IsRead    bool      `gorm:"default:false" json:"is_read"`
+    KampanyeID *uint    `gorm:"index" json:"kampanye_id,omitempty"` // diisi jika berasal dari kampanye/broadcast
+    ArchivedAt *time.Time `gorm:"index" json:"archived_at"` // diisi saat user mengarsipkan; tidak tampil di inbox utama
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
 
 type FCMNotificationData struct {
-	Type      string `json:"type"` // report_status, chat, appointment, INIII BARU 3 KALO MAU DITAMBAH JAN LUPA HANDLE FRONT END JUGA NOTIFICATIONS CHANNEL/Notification Service.
+	Type string `json:"type"` // tipe lama yang dikenal aplikasi; daftar jenis di GET /api/notification-schema
+	// Kind, Version dan Payload diisi oleh registry jenis notifikasi
+	// (handlers.JenisNotifikasi). Payload adalah JSON payload bertipe sesuai Kind.
+	Kind    string `json:"kind,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Payload string `json:"payload,omitempty"`
+	// ReportID dan Status: field lama, tetap diisi untuk aplikasi versi lama
 	ReportID  string `json:"reportId"`
 	Status    string `json:"status"`
 	UpdatedBy uint   `json:"updatedBy"`
@@ -24,6 +32,8 @@ type FCMNotificationData struct {
 	Notes     string `json:"notes"`
 	DeepLink  string `json:"deepLink"`
 	ImageURL  string `json:"imageUrl,omitempty"`
+	// Urgent: tetap dikirim saat jam tenang user
+	Urgent bool `json:"urgent,omitempty"`
 }
 
 type FCMNotificationContent struct {
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// NotificationOutbox adalah antrean push notification dan email. Baris ditulis dalam transaksi
+// yang sama dengan perubahan data, lalu dikirim oleh worker di latar belakang.
+type NotificationOutbox struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	NotificationID uint       `gorm:"index" json:"notification_id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	Saluran        string     `gorm:"size:10;not null;default:'push'" json:"saluran"` // push, email, sms atau whatsapp
+	Title          string     `gorm:"not null" json:"title"`
+	Body           string     `gorm:"not null" json:"body"`
+	Payload        string     `gorm:"type:json" json:"payload"` // JSON dari FCMNotificationData
+	Status         string     `gorm:"size:20;not null;default:'pending';index:idx_outbox_antrean,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_outbox_antrean,priority:2" json:"next_attempt_at"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	SentAt         *time.Time `json:"sent_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
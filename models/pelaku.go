@@ -21,7 +21,8 @@ type Pelaku struct {
 	Kebangsaan           string    `json:"kebangsaan"`
 	HubunganDenganKorban string    `json:"hubungan_dengan_korban"`
 	KeteranganLainnya    string    `json:"keterangan_lainnya"`
-	DokumentasiPelaku    string    `json:"dokumentasi_pelaku"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
+
+	Dokumentasi []DokumentasiFile `json:"dokumentasi_pelaku" gorm:"polymorphic:Owner;polymorphicValue:pelaku"`
 }
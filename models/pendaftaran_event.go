@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PendaftaranEvent mencatat masyarakat yang mendaftar ke sebuah event.
+type PendaftaranEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventID   uint      `gorm:"not null;uniqueIndex:idx_pendaftaran_event" json:"event_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_pendaftaran_event;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
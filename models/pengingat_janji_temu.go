@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PengingatJanjiTemu mencatat pengingat yang sudah dikirim sehingga setiap
+// kombinasi janji temu, penerima dan offset hanya dikirim sekali (juga setelah restart).
+type PengingatJanjiTemu struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	JanjiTemuID uint      `gorm:"not null;uniqueIndex:idx_pengingat_unik" json:"janji_temu_id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_pengingat_unik" json:"user_id"`
+	OffsetMenit int       `gorm:"not null;uniqueIndex:idx_pengingat_unik" json:"offset_menit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
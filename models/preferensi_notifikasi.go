@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PreferensiNotifikasi menyimpan pilihan notifikasi satu user. Tipe/saluran yang
+// tidak disebut di Pengaturan memakai default: push aktif, email dan SMS tidak (opt-in).
+// Notifikasi tetap disimpan di tabel notifications walaupun salurannya dimatikan.
+type PreferensiNotifikasi struct {
+	ID     uint `gorm:"primaryKey" json:"id"`
+	UserID uint `gorm:"not null;uniqueIndex" json:"user_id"`
+
+	// Pengaturan: JSON {"<tipe>": {"<saluran>": true/false}}
+	Pengaturan string `gorm:"type:json" json:"pengaturan"`
+
+	// Jam tenang dalam format "HH:MM" di zona waktu user; kosong berarti tidak aktif.
+	JamTenangMulai   string `gorm:"size:5" json:"jam_tenang_mulai"`
+	JamTenangSelesai string `gorm:"size:5" json:"jam_tenang_selesai"`
+	ZonaWaktu        string `gorm:"size:64;default:'Asia/Jakarta'" json:"zona_waktu"`
+	// Bahasa teks notifikasi: "id" atau "en"
+	Bahasa string `gorm:"size:10;not null;default:'id'" json:"bahasa"`
+
+	// ModeSamaran: isi push diganti teks netral dan payload tidak memuat ID laporan
+	// atau catatan; detail hanya bisa dibaca di aplikasi lewat daftar notifikasi.
+	ModeSamaran bool `gorm:"not null;default:false" json:"mode_samaran"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
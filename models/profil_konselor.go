@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ProfilKonselor melengkapi user admin yang melayani konsultasi. Spesialisasi dan
+// Bahasa disimpan sebagai daftar dipisah koma, misalnya "trauma,anak" dan "id,en".
+type ProfilKonselor struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	User         User      `json:"-" gorm:"foreignKey:UserID"`
+	UserID       uint      `json:"user_id" gorm:"uniqueIndex"`
+	NamaTampilan string    `json:"nama_tampilan"`
+	Spesialisasi string    `json:"spesialisasi"`
+	Bahasa       string    `json:"bahasa"`
+	JenisKelamin string    `json:"jenis_kelamin"`
+	Bio          string    `json:"bio" gorm:"type:text"`
+	Foto         string    `json:"foto"`
+	Aktif        bool      `json:"aktif"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SensitiveDataAccess mencatat setiap kali field sensitif (NIK, telepon, alamat)
+// ditampilkan tanpa disamarkan.
+type SensitiveDataAccess struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Role       string    `gorm:"size:20;not null" json:"role"`
+	Resource   string    `gorm:"size:50;not null;index:idx_sensitive_resource" json:"resource"`
+	ResourceID string    `gorm:"size:100;not null;index:idx_sensitive_resource" json:"resource_id"`
+	Fields     string    `gorm:"type:text;not null" json:"fields"`
+	IPAddress  string    `gorm:"size:64" json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+}
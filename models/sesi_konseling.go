@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// SesiKonseling mencatat hasil pelaksanaan sebuah janji temu konsultasi.
+type SesiKonseling struct {
+	ID                  uint       `json:"id" gorm:"primaryKey"`
+	JanjiTemu           JanjiTemu  `json:"janji_temu" gorm:"foreignKey:JanjiTemuID"`
+	JanjiTemuID         uint       `json:"janji_temu_id" gorm:"uniqueIndex"`
+	Konselor            User       `json:"konselor" gorm:"foreignKey:KonselorID"`
+	KonselorID          uint       `json:"konselor_id" gorm:"index"`
+	Kehadiran           string     `json:"kehadiran"`
+	CatatanKonselor     string     `json:"catatan_konselor" gorm:"type:text"`
+	TindakLanjut        string     `json:"tindak_lanjut" gorm:"type:text"`
+	TanggalTindakLanjut *time.Time `json:"tanggal_tindak_lanjut"`
+	NoRegistrasi        *string    `json:"no_registrasi,omitempty" gorm:"size:191;index"`
+	DicatatOlehID       uint       `json:"dicatat_oleh_id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TemplateNotifikasi menyimpan perubahan admin atas template bawaan notifikasi.
+// Jika baris untuk kunci dan bahasa tertentu tidak ada, template bawaan di kode dipakai.
+type TemplateNotifikasi struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Kunci       string    `gorm:"size:100;not null;uniqueIndex:idx_template_bahasa" json:"kunci"`
+	Bahasa      string    `gorm:"size:10;not null;uniqueIndex:idx_template_bahasa" json:"bahasa"`
+	Judul       string    `gorm:"type:text;not null" json:"judul"`
+	Isi         string    `gorm:"type:text;not null" json:"isi"`
+	UpdatedByID uint      `json:"updated_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
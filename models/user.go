@@ -24,6 +24,7 @@ type User struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 
 	// Add new Field for NotifiactionToken:
+	// Token terakhir saja; pengiriman push memakai tabel user_devices (UserDevice).
 	NotificationToken string  `json:"notification_token" gorm:"size:255;default:null"`
 }
 
@@ -32,6 +33,9 @@ type LoginCredentials struct {
 	Password    string `json:"password" binding:"required"`
 	Username    string `json:"username"`
 	PhoneNumber string `json:"phone_number"`
+
+	// Opsional: perangkat yang dipakai login, untuk push notification
+	DeviceInfo
 }
 
 type Claims struct {
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserDevice adalah perangkat (token FCM) milik user. Satu user bisa login di
+// beberapa perangkat sekaligus dan push dikirim ke semua perangkat yang aktif.
+type UserDevice struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Token      string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	Platform   string    `gorm:"size:20" json:"platform"`
+	AppVersion string    `gorm:"size:50" json:"app_version"`
+	Active     bool      `gorm:"not null;default:true" json:"active"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+
+	// Catatan kegagalan kirim; token yang ditolak permanen oleh FCM dinonaktifkan.
+	FailureCount  int        `gorm:"not null;default:0" json:"failure_count"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	LastFailureAt *time.Time `json:"last_failure_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeviceInfo adalah data perangkat yang dikirim aplikasi saat login/registrasi.
+type DeviceInfo struct {
+	NotificationToken string `json:"notification_token" form:"notification_token"`
+	Platform          string `json:"platform" form:"platform"`
+	AppVersion        string `json:"app_version" form:"app_version"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UsulanJadwalJanjiTemu menyimpan riwayat usulan perubahan jadwal janji temu,
+// baik dari admin maupun dari masyarakat.
+type UsulanJadwalJanjiTemu struct {
+	ID                        uint       `json:"id" gorm:"primaryKey"`
+	JanjiTemuID               uint       `json:"janji_temu_id" gorm:"index"`
+	DiusulkanOlehID           uint       `json:"diusulkan_oleh_id"`
+	PeranPengusul             string     `json:"peran_pengusul"`
+	WaktuDimulaiSebelumnya    time.Time  `json:"waktu_dimulai_sebelumnya"`
+	WaktuSelesaiSebelumnya    time.Time  `json:"waktu_selesai_sebelumnya"`
+	WaktuDimulaiUsulan        time.Time  `json:"waktu_dimulai_usulan"`
+	WaktuSelesaiUsulan        time.Time  `json:"waktu_selesai_usulan"`
+	StatusJanjiTemuSebelumnya string     `json:"status_janji_temu_sebelumnya"`
+	Alasan                    string     `json:"alasan" gorm:"type:text"`
+	Status                    string     `json:"status" gorm:"default:'Menunggu'"`
+	DitanggapiOlehID          *uint      `json:"ditanggapi_oleh_id,omitempty"`
+	AlasanPenolakan           string     `json:"alasan_penolakan" gorm:"type:text"`
+	DitanggapiPada            *time.Time `json:"ditanggapi_pada,omitempty"`
+	CreatedAt                 time.Time  `json:"created_at"`
+	UpdatedAt                 time.Time  `json:"updated_at"`
+}
@@ -16,6 +16,8 @@ func SetAdminRoutes(app *fiber.App) {
 	adminGroup.Get("/profile", handlers.GetUserProfile)
 	adminGroup.Put("/edit-profile", handlers.UpdateUserProfile)
 	adminGroup.Put("/change-password", handlers.ChangePassword)
+	adminGroup.Post("/logout", handlers.LogoutUser)
+	adminGroup.Get("/devices", handlers.GetUserDevices)
 
 	adminGroup.Get("/emergency-contact", handlers.GetEmergencyContact)
 	adminGroup.Put("/emergency-contact-edit", handlers.UpdateEmergencyContact)
@@ -23,6 +25,7 @@ func SetAdminRoutes(app *fiber.App) {
 	adminGroup.Get("/laporans", handlers.GetLatestReports)
 	adminGroup.Get("/laporans-pagination", handlers.GetLatestReportsPagination)
 	adminGroup.Get("/detail-laporan/:no_registrasi", handlers.GetLaporanByNoRegistrasi)
+	adminGroup.Get("/sensitive-access-logs", handlers.GetSensitiveDataAccessLogs)
 	adminGroup.Put("/lihat-laporan/:no_registrasi", handlers.AdminLihatLaporan)
 	adminGroup.Put("/proses-laporan/:no_registrasi", handlers.AdminProsesLaporan)
 	adminGroup.Put("laporan-selesai/:no_registrasi", handlers.SelesaikanLaporan)
@@ -38,6 +41,11 @@ func SetAdminRoutes(app *fiber.App) {
 	adminGroup.Post("/create-korban-kekerasan", handlers.CreateKorban)
 	adminGroup.Put("/edit-korban-kekerasan/:id", handlers.UpdateKorban)
 
+	adminGroup.Get("/kebutuhan-korban/:korban_id", handlers.GetKebutuhanKorban)
+	adminGroup.Post("/create-kebutuhan-korban", handlers.CreateKebutuhanKorban)
+	adminGroup.Put("/edit-kebutuhan-korban/:id", handlers.UpdateKebutuhanKorban)
+	adminGroup.Delete("/delete-kebutuhan-korban/:id", handlers.DeleteKebutuhanKorban)
+
 	adminGroup.Get("/violence-categories", handlers.GetAllViolenceCategories)
 	adminGroup.Get("/detail-violence-category/:id", handlers.GetViolenceCategoryByID)
 	adminGroup.Post("/create-violence-category", handlers.CreateViolenceCategory)
@@ -60,11 +68,53 @@ func SetAdminRoutes(app *fiber.App) {
 	adminGroup.Get("/detail-janjitemu/:id", handlers.AdminJanjiTemuByID)
 	adminGroup.Put("/approve-janjitemu/:id", handlers.AdminApproveJanjiTemu)
 	adminGroup.Put("/cancel-janjitemu/:id", handlers.AdminCancelJanjiTemu)
+	adminGroup.Get("/jadwal-konselor", handlers.AdminGetJadwalKonselor)
+	adminGroup.Post("/create-jadwal-konselor", handlers.AdminCreateJadwalKonselor)
+	adminGroup.Delete("/delete-jadwal-konselor/:id", handlers.AdminDeleteJadwalKonselor)
+	adminGroup.Get("/janjitemu/:id/ics", handlers.DownloadJanjiTemuICS)
+	adminGroup.Put("/usul-ulang-janjitemu/:id", handlers.UsulkanJadwalUlangJanjiTemu)
+	adminGroup.Put("/terima-usulan-janjitemu/:id", handlers.TerimaUsulanJadwalJanjiTemu)
+	adminGroup.Put("/tolak-usulan-janjitemu/:id", handlers.TolakUsulanJadwalJanjiTemu)
+	adminGroup.Get("/riwayat-usulan-janjitemu/:id", handlers.GetRiwayatUsulanJadwalJanjiTemu)
+	adminGroup.Get("/profil-konselor", handlers.AdminGetProfilKonselor)
+	adminGroup.Put("/profil-konselor", handlers.AdminSimpanProfilKonselor)
+	adminGroup.Get("/sesi-konseling", handlers.AdminGetSesiKonseling)
+	adminGroup.Get("/detail-sesi-konseling/:id", handlers.AdminGetSesiKonselingByID)
+	adminGroup.Post("/create-sesi-konseling", handlers.AdminCreateSesiKonseling)
+	adminGroup.Put("/edit-sesi-konseling/:id", handlers.AdminUpdateSesiKonseling)
+	adminGroup.Get("/statistik-kehadiran-konseling", handlers.AdminGetStatistikKehadiranKonseling)
+	adminGroup.Get("/kalender-feed", handlers.GetKalenderFeed)
+	adminGroup.Post("/kalender-feed/regenerate", handlers.RegenerateKalenderFeed)
 	adminGroup.Get("/status-stats", handlers.GetLaporanStatusCount)
 
 	adminGroup.Get("/report", handlers.GetReportedByClient)
 	adminGroup.Post("/report/client", handlers.ReportClient)
 	adminGroup.Post("/notification/push", handlers.SendPushNotification)
+	adminGroup.Get("/notification-outbox/dead", handlers.AdminGetDeadLetterNotifications)
+	adminGroup.Put("/notification-outbox/:id/retry", handlers.AdminRetryDeadLetterNotification)
+	adminGroup.Get("/users-tanpa-perangkat", handlers.AdminGetUserTanpaPerangkat)
+	adminGroup.Get("/kampanye-notifikasi", handlers.AdminGetKampanyeNotifikasi)
+	adminGroup.Get("/detail-kampanye-notifikasi/:id", handlers.AdminGetKampanyeNotifikasiByID)
+	adminGroup.Post("/create-kampanye-notifikasi", handlers.AdminCreateKampanyeNotifikasi)
+	adminGroup.Put("/batal-kampanye-notifikasi/:id", handlers.AdminBatalKampanyeNotifikasi)
+	adminGroup.Get("/template-notifikasi", handlers.AdminGetTemplateNotifikasi)
+	adminGroup.Put("/template-notifikasi/:kunci/:bahasa", handlers.AdminSimpanTemplateNotifikasi)
+	adminGroup.Delete("/template-notifikasi/:kunci/:bahasa", handlers.AdminResetTemplateNotifikasi)
+
+	// Inbox notifikasi admin
+	adminGroup.Get("/inbox-notifikasi", handlers.GetInboxNotifikasi)
+	adminGroup.Get("/ringkasan-inbox-notifikasi", handlers.GetRingkasanInbox)
+	adminGroup.Get("/read-notification", handlers.MarkNotificationAsRead)
+	adminGroup.Put("/read-all-notification", handlers.MarkAllNotificationsAsRead)
+	adminGroup.Put("/arsip-notifikasi", handlers.ArsipkanNotifikasi)
+	adminGroup.Put("/keluarkan-arsip-notifikasi", handlers.KeluarkanArsipNotifikasi)
+	adminGroup.Delete("/delete-notification", handlers.HapusNotifikasi)
+	adminGroup.Get("/stream-notifikasi", handlers.StreamNotifikasi)
+	adminGroup.Post("/stream-token", handlers.BuatTokenStream)
+	adminGroup.Get("/langganan-notifikasi", handlers.AdminGetLanggananNotifikasi)
+	adminGroup.Get("/preferensi-notifikasi", handlers.GetPreferensiNotifikasi)
+	adminGroup.Put("/preferensi-notifikasi", handlers.UpdatePreferensiNotifikasi)
+	adminGroup.Put("/langganan-notifikasi/:peristiwa", handlers.AdminUpdateLanggananNotifikasi)
 }
 
 /*========= ||  Endpoint yang hanya bisa diakses oleh masyarakat || ====================*/
@@ -75,6 +125,8 @@ func SetMasyarakatRoutes(app *fiber.App) {
 	masyarakatGroup.Get("/profile", handlers.GetUserProfile)
 	masyarakatGroup.Put("/edit-profile", handlers.UpdateUserProfile)
 	masyarakatGroup.Put("/change-password", handlers.ChangePassword)
+	masyarakatGroup.Post("/logout", handlers.LogoutUser)
+	masyarakatGroup.Get("/devices", handlers.GetUserDevices)
 
 	masyarakatGroup.Get("/kategori-kekerasan", handlers.GetAllViolenceCategories)
 	masyarakatGroup.Get("/kategori-kekerasan/:id", handlers.GetViolenceCategoryByID)
@@ -89,28 +141,51 @@ func SetMasyarakatRoutes(app *fiber.App) {
 	masyarakatGroup.Post("/create-korban-kekerasan", handlers.CreateKorban)
 	masyarakatGroup.Put("/edit-korban-kekerasan/:id", handlers.UpdateKorban)
 
-	masyarakatGroup.Post("/create-pelaku-kekerasan", handlers.CreateKorban)
-	masyarakatGroup.Put("/edit-pelaku-kekerasan/:id", handlers.UpdateKorban)
+	masyarakatGroup.Post("/create-pelaku-kekerasan", handlers.CreatePelaku)
+	masyarakatGroup.Put("/edit-pelaku-kekerasan/:id", handlers.UpdatePelaku)
 
 	masyarakatGroup.Post("/create-korban-kekerasan", handlers.CreateKorban)
 	masyarakatGroup.Put("/edit-korban-kekerasan/:id", handlers.UpdateKorban)
 
+	masyarakatGroup.Get("/konselor", handlers.GetDaftarKonselor)
+	masyarakatGroup.Get("/detail-konselor/:id", handlers.GetDetailKonselor)
+	masyarakatGroup.Get("/jadwal-konselor-tersedia", handlers.GetJadwalKonselorTersedia)
 	masyarakatGroup.Get("/janjitemus", handlers.GetUserJanjiTemus)
 	masyarakatGroup.Get("/detail-janjitemu/:id", handlers.GetJanjiTemuByID)
 	masyarakatGroup.Post("/create-janjitemu", handlers.MasyarakatCreateJanjiTemu)
 	masyarakatGroup.Put("/edit-janjitemu/:id", handlers.MasyarakatEditJanjiTemu)
 	masyarakatGroup.Put("/batal-janjitemu/:id", handlers.MasyarakatCancelJanjiTemu)
+	masyarakatGroup.Get("/janjitemu/:id/ics", handlers.DownloadJanjiTemuICS)
+	masyarakatGroup.Put("/usul-ulang-janjitemu/:id", handlers.UsulkanJadwalUlangJanjiTemu)
+	masyarakatGroup.Put("/terima-usulan-janjitemu/:id", handlers.TerimaUsulanJadwalJanjiTemu)
+	masyarakatGroup.Put("/tolak-usulan-janjitemu/:id", handlers.TolakUsulanJadwalJanjiTemu)
+	masyarakatGroup.Get("/riwayat-usulan-janjitemu/:id", handlers.GetRiwayatUsulanJadwalJanjiTemu)
+	masyarakatGroup.Get("/kalender-feed", handlers.GetKalenderFeed)
+	masyarakatGroup.Post("/kalender-feed/regenerate", handlers.RegenerateKalenderFeed)
+
+	masyarakatGroup.Post("/daftar-event/:id", handlers.DaftarEvent)
+	masyarakatGroup.Delete("/batal-daftar-event/:id", handlers.BatalDaftarEvent)
 
 	masyarakatGroup.Get("/content", handlers.GetAllContents)
 	masyarakatGroup.Get("/detail-content/:id", handlers.GetContentByID)
 
 	// * New Router for handler Notification
 	masyarakatGroup.Get("/update-notification-token", handlers.UpdateNotificationTokenHandler)
+	masyarakatGroup.Get("/preferensi-notifikasi", handlers.GetPreferensiNotifikasi)
+	masyarakatGroup.Put("/preferensi-notifikasi", handlers.UpdatePreferensiNotifikasi)
 
 	// * New router for retireve Pagination Notification
 	masyarakatGroup.Get("/retrieve-notification", handlers.GetUserNotifications)
 	masyarakatGroup.Get("/unread-notification-count", handlers.GetUnreadNotificationsCount)
 	masyarakatGroup.Get("/read-notification", handlers.MarkNotificationAsRead)
+	masyarakatGroup.Get("/inbox-notifikasi", handlers.GetInboxNotifikasi)
+	masyarakatGroup.Get("/ringkasan-inbox-notifikasi", handlers.GetRingkasanInbox)
+	masyarakatGroup.Put("/read-all-notification", handlers.MarkAllNotificationsAsRead)
+	masyarakatGroup.Put("/arsip-notifikasi", handlers.ArsipkanNotifikasi)
+	masyarakatGroup.Put("/keluarkan-arsip-notifikasi", handlers.KeluarkanArsipNotifikasi)
+	masyarakatGroup.Delete("/delete-notification", handlers.HapusNotifikasi)
+	masyarakatGroup.Get("/stream-notifikasi", handlers.StreamNotifikasi)
+	masyarakatGroup.Post("/stream-token", handlers.BuatTokenStream)
 
 	masyarakatGroup.Get("/notification/push", handlers.SendPushNotification)
 	masyarakatGroup.Post("/report/admin", handlers.UserReportAdmin)
@@ -124,10 +199,17 @@ func RoutesWithOutLogin(app *fiber.App) {
 	})
 
 	app.Get("/api/emergency-contact", handlers.EmergencyContact)
+	app.Get("/api/notification-schema", handlers.GetSkemaNotifikasi)
+	// EventSource browser: token dari POST /api/{admin,masyarakat}/stream-token
+	app.Get("/api/stream-notifikasi", handlers.StreamNotifikasiDenganToken)
 	app.Get("/api/publik-content", handlers.GetAllContents)
 	app.Get("/api/detail-content/:id", handlers.GetContentByID)
 	app.Get("api/publik-event", handlers.GetAllEvent)
 	app.Get("/api/detail-event/:id", handlers.GetEventByID)
+	app.Get("/api/event/:id/ics", handlers.DownloadEventICS)
+	app.Get("/api/kalender/:token", handlers.KalenderFeedICS)
+	app.Get("/api/publik/konselor", handlers.GetDaftarKonselor)
+	app.Get("/api/publik/detail-konselor/:id", handlers.GetDetailKonselor)
 	app.Get("/hello", handlers.HelloMasyarakat)
 	app.Get("/api/publik/kategori-kekerasan", handlers.GetAllViolenceCategories)
 	app.Get("/api/publik/detail-kategori-kekerasan/:id", handlers.GetViolenceCategoryByID)